@@ -1,11 +1,24 @@
 package testfill
 
 import (
+	"context"
+	"database/sql"
+	"encoding"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"math/rand"
+	"net/url"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"text/template"
 	"time"
 )
 
@@ -16,30 +29,195 @@ const (
 	TagFactory   = "factory:"
 	TagUnmarshal = "unmarshal:"
 	TagVariant   = "variants:"
+	TagJSON      = "testfill_json"
+	TagOverrides = "testfill_overrides"
+	TagSeq       = "seq"
+	TagRand      = "rand:"
+	TagRepeat    = "repeat:"
+	TagProvider  = "provider:"
+	TagTimeZone  = "tz:"
+	TagWhen      = "when:"
+	TagClamp     = "clamp:"
+	TagRequired  = "required"
+	TagTemplate  = "tmpl:"
+	TagNow       = "now"
+	TagUnix      = "unix:"
+	TagRange     = "range:"
+	TagOneOf     = "oneof:"
+	TagCSV       = "csv"
+	TagShallow   = "fill:shallow"
+	TagTuple     = "fill:tuple:"
+	TagPinned    = "fill:variant="
+	TagMacro     = "@"
+	TagCopy      = "copy:"
+	TagEnum      = "enum:"
+	TagUUID      = "uuid"
+	TagIncr      = "incr"
+	TagComment   = "#"
+	TagBlank     = "blank"
+	TagDates     = "dates:"
+	TagNil       = "nil"
+	TagNull      = "null"
+	TagHex       = "hex:"
+	TagJSONFill  = "unmarshal+fill:"
+	TagIgnore    = "-"
+	TagCountOf   = "fill:count="
+	TagRef       = "ref:"
+	TagFake      = "fake:"
+	TagFlags     = "flags:"
 )
 
+// DefaultVariant is the reserved variant name that selects the base testfill tag, so
+// FillWithVariant(x, DefaultVariant) behaves like Fill(x) and "variants:default,admin"
+// mixes default and admin elements. A testfill_default tag is never read.
+const DefaultVariant = "default"
+
 // Error messages
 const (
 	ErrNotStruct            = "testfill: expected struct, got %T"
+	ErrNilTarget            = "testfill: cannot fill through a nil %T"
+	ErrFillElement          = "testfill: failed to fill element %d: %w"
+	ErrFillInput            = "testfill: failed to fill input %d: %w"
+	ErrFillMapValue         = "testfill: failed to fill map value for key %v: %w"
 	ErrNestedStruct         = "testfill: failed to fill nested struct %s: %w"
 	ErrNestedStructPtr      = "testfill: failed to fill nested struct pointer %s: %w"
+	ErrNestedArray          = "testfill: failed to fill nested array %s element %d: %w"
 	ErrSetField             = "testfill: failed to set field %s: %w"
+	ErrPostFillStruct       = "testfill: post-fill hook for %s failed: %w"
+	ErrPostFill             = "post-fill hook for %s failed: %w"
 	ErrUnsupportedStruct    = "unsupported struct type %s"
 	ErrUnsupportedField     = "unsupported field type %s"
 	ErrUnsupportedSliceType = "unsupported slice element type %s"
 	ErrUnsupportedMapType   = "unsupported map type %s -> %s"
 	ErrInvalidMapFormat     = "invalid map format: %s"
+	ErrInvalidRepeat        = "invalid repeat format: %s (expected format: repeat:count:value)"
+	ErrInvalidRange         = "invalid range %s (expected format: range:start..end[..step])"
+	ErrRangeStep            = "range %s never reaches its end with step %d"
+	ErrRangeType            = "range is not supported for %s elements"
+	ErrRangeCount           = "range %s has more than %d values"
+	ErrInvalidDates         = "invalid dates format: %s (expected format: dates:start:step:count)"
+	ErrDatesPart            = "invalid dates %s %q"
+	ErrDatesType            = "dates is not supported for %s elements"
+	ErrHexType              = "hex is not supported for %s elements"
+	ErrInvalidHex           = "invalid hex value %q: %w"
+	ErrOneOfIndex           = "%s has no option for element %d"
+	ErrArrayLength          = "array of length %d cannot be filled with %d values"
+	ErrSliceCount           = "slice count %d exceeds the maximum of %d"
+	ErrInvalidSeq           = "invalid sequence %s: %w"
+	ErrUnknownRandMode      = "unknown rand mode %q"
+	ErrUnsupportedRandType  = "unsupported type %s for random values"
+	ErrRandLength           = "invalid rand string length %q"
+	ErrRandCharset          = "unknown rand charset %q"
+	ErrUnknownFake          = "unknown fake category %q"
+	ErrFakeType             = "fake is not supported for %s fields"
+	ErrNoFlags              = "no flags registered for %s"
+	ErrUnknownFlag          = "unknown flag %q for %s"
+	ErrFlagOverflow         = "flags %s value %d overflows %s"
+	ErrFieldHookType        = "field hook returned %s, but field expects %s"
+	ErrUniqueExhausted      = "could not generate a unique %s value after %d attempts"
+	ErrUnknownReference     = "unknown field %s referenced"
+	ErrCircularReference    = "circular reference to field %s"
+	ErrNilEmbedded          = "field %s is promoted through a nil embedded pointer"
+	ErrCountType            = "count field %s is %s, not an integer"
+	ErrCopyType             = "cannot copy field %s of type %s to %s"
+	ErrInvalidCondition     = "invalid when condition: %s (expected format: when:Field==value:tag)"
+	ErrInvalidClamp         = "invalid clamp format: %s (expected format: clamp:min:max:value)"
+	ErrClampBound           = "invalid clamp bound %q: %w"
+	ErrClampRange           = "clamp minimum %s is greater than maximum %s"
+	ErrClampType            = "clamp is not supported for %s"
+	ErrIncrType             = "incr is not supported for %s"
+	ErrTupleType            = "fill:tuple is not supported for %s"
+	ErrTupleCount           = "tuple for %s expects %d values, got %d"
+	ErrRequired             = "required field is not set"
+	ErrUnexportedTag        = "field is unexported and cannot be filled"
+	ErrInvalidEnum          = "invalid enum format: %s (expected format: enum:value:allowed1,allowed2)"
+	ErrEnumValue            = "value %v is not one of the allowed values %s"
+	ErrInvalidOverride      = "invalid override %s (expected format: index.Field=value)"
+	ErrOverrideIndex        = "override index %d out of range for %d elements"
+	ErrOverrideField        = "invalid override %s: %w"
+	ErrUnknownField         = "unknown field %s"
+	ErrTemplate             = "invalid template %q: %w"
+	ErrUnknownDirective     = "unknown tag directive %q"
+	ErrMisspelledDirective  = "unknown tag directive %q (did you mean %q?)"
+	ErrContainerDepth       = "unsupported container type %s: containers can be nested at most %d levels deep"
+	ErrNestedStructFill     = "%s cannot be filled with %s: give each nested struct as fill or a variant name, e.g. a=fill;b=admin"
+	ErrConverterType        = "converter for %s returned %T"
 	ErrFactoryNotFound      = "factory function %s not found"
 	ErrFactoryArgCount      = "factory function %s expects %d arguments, got %d"
 	ErrFactoryPanic         = "factory function panicked: %v"
 	ErrFactoryReturnCount   = "factory function %s must return exactly one value"
 	ErrFactoryReturnType    = "factory function %s returns %s, but field expects %s"
+	ErrFactoryReturnNil     = "factory function %s returned nil, but field expects %s"
 	ErrFactoryArgConvert    = "factory function %s argument %d: %w"
+	ErrFactoryFailed        = "factory function %s failed: %w"
 	ErrStringConvert        = "cannot convert %q to %s: %w"
 	ErrUnsupportedParam     = "unsupported parameter type %s for factory function arguments"
+	ErrNoImplementation     = "no implementation registered for interface %s"
+	ErrImplementationType   = "implementation %s of interface %s must be a struct or a pointer to a struct implementing it"
+	ErrTimeZone             = "invalid time zone: %w"
+	ErrUnixTime             = "invalid unix time %s: %w"
+	ErrTimeInZone           = "cannot parse %q as a time in %s (expected RFC3339 or a wall-clock time such as 2006-01-02 15:04:05)"
+	ErrUnknownMacro         = "unknown macro %s"
+	ErrNoProvider           = "no provider registered for key %s"
+	ErrProvider             = "provider failed for key %s: %w"
+	ErrUnknownRef           = "no object registered as %s"
+	ErrRefType              = "object %s is %s, but field expects %s"
 	ErrJSONUnmarshal        = "failed to unmarshal JSON: %w"
+	ErrBigNumber            = "cannot convert %q to %s"
+	ErrInputJSON            = "testfill: failed to unmarshal input JSON: %w"
+	ErrCSVRow               = "testfill: CSV row has %d values for %d columns"
+	ErrOverrideJSON         = "testfill: failed to apply override JSON: %w"
+	ErrJSONTrailingData     = "invalid data after top-level JSON value"
+	ErrRawMessage           = "invalid JSON for json.RawMessage: %s"
+)
+
+// FieldError reports which field failed to be filled.
+// Path holds the enclosing fields from the outermost to the innermost one, with slice indexes
+// and map keys as "[i]" segments, and Field is the name of the field that failed.
+// Retrieve it from a Fill error with errors.As.
+type FieldError struct {
+	Path  []string
+	Field string
+	Cause error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Errorf(ErrSetField, joinPath(append(append([]string(nil), e.Path...), e.Field)), e.Cause).Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Cause
+}
+
+// Error kinds, matched with errors.Is against the errors returned by the fill functions to
+// tell failure categories apart without inspecting messages.
+var (
+	// ErrConversion reports a tag value that cannot be converted to the field type.
+	ErrConversion = errors.New("testfill: conversion failed")
+	// ErrFactory reports a factory that is missing, fails, panics or returns an unusable value.
+	ErrFactory = errors.New("testfill: factory failed")
+	// ErrUnsupported reports a field type testfill cannot fill from the given tag.
+	ErrUnsupported = errors.New("testfill: unsupported type")
 )
 
+// kindError tags an error with its error kind while keeping its message.
+type kindError struct {
+	kind error
+	err  error
+}
+
+func withKind(kind, err error) error {
+	return &kindError{kind: kind, err: err}
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.err, e.kind}
+}
+
 // =====================================================
 // Main API Functions
 // =====================================================
@@ -47,24 +225,11 @@ const (
 // Fill populates zero-valued fields in a struct based on testfill tags.
 // It takes a struct value and returns a copy with fields filled according to their tags.
 // Supports nested structs, pointers, slices, maps, and factory functions.
+// A slice or map of structs is also accepted, in which case every element is filled.
+// Fields of sync types such as sync.Mutex are never filled; since the input is copied,
+// structs holding locks should be passed before they are used.
 func Fill[T any](input T) (T, error) {
-	var zero T
-	inputValue := reflect.ValueOf(input)
-	inputType := reflect.TypeOf(input)
-
-	if inputType.Kind() != reflect.Struct {
-		return zero, fmt.Errorf(ErrNotStruct, input)
-	}
-
-	// Create a copy to work with
-	resultValue := reflect.New(inputType).Elem()
-	resultValue.Set(inputValue)
-
-	if err := fillStruct(resultValue); err != nil {
-		return zero, err
-	}
-
-	return resultValue.Interface().(T), nil
+	return fill(newFiller(nil), input, nil)
 }
 
 // MustFill is like Fill but panics on error.
@@ -81,25 +246,10 @@ func MustFill[T any](input T) T {
 // FillWithVariant populates zero-valued fields in a struct based on testfill tags with a specific variant.
 // It takes a struct value and a variant name, returning a copy with fields filled according to their
 // variant-specific tags (e.g., testfill_admin) or falling back to default testfill tags.
+// The DefaultVariant name is equivalent to Fill.
 // Supports nested structs, pointers, slices, maps, and factory functions.
 func FillWithVariant[T any](input T, variant string) (T, error) {
-	var zero T
-	inputValue := reflect.ValueOf(input)
-	inputType := reflect.TypeOf(input)
-
-	if inputType.Kind() != reflect.Struct {
-		return zero, fmt.Errorf(ErrNotStruct, input)
-	}
-
-	// Create a copy to work with
-	resultValue := reflect.New(inputType).Elem()
-	resultValue.Set(inputValue)
-
-	if err := fillStructWithVariant(resultValue, variant); err != nil {
-		return zero, err
-	}
-
-	return resultValue.Interface().(T), nil
+	return fill(newFiller(nil), input, []string{variant})
 }
 
 // MustFillWithVariant is like FillWithVariant but panics on error.
@@ -113,407 +263,3413 @@ func MustFillWithVariant[T any](input T, variant string) T {
 	return result
 }
 
-// RegisterFactory registers a factory function that can be called from struct tags.
-// The function must return exactly one value that matches the field type.
-// Factory functions can accept string arguments that will be converted to the appropriate types.
-//
-// Example:
-//	// Register a factory function
-//	testfill.RegisterFactory("uuid", func() string { return "test-uuid-123" })
-//	
-//	// Use in struct tag
-//	type User struct {
-//		ID string `testfill:"factory:uuid"`
-//	}
-func RegisterFactory(name string, fn interface{}) {
-	factoryRegistry[name] = fn
+// FillWithVariants is like FillWithVariant but composes several variants in priority order. For each
+// field, the first variant with a matching tag wins (testfill_admin, then testfill_eu for "admin", "eu"),
+// falling back to the default testfill tag. This allows combining orthogonal dimensions such as role and region.
+func FillWithVariants[T any](input T, variants ...string) (T, error) {
+	return fill(newFiller(nil), input, variants)
 }
 
-// =====================================================
-// Core struct filling logic
-// =====================================================
+// FillJSON unmarshals jsonData into a new T and then fills the fields the JSON left zero
+// based on their testfill tags. This is handy for partial JSON fixtures.
+// Options apply to both the unmarshaling and the filling.
+func FillJSON[T any](jsonData []byte, opts ...Option) (T, error) {
+	var input T
+	f := newFiller(opts)
+	if err := f.decodeJSON(&input, string(jsonData)); err != nil {
+		return input, fmt.Errorf(ErrInputJSON, err)
+	}
 
-func fillStruct(structValue reflect.Value) error {
-	return fillStructWithVariant(structValue, "")
+	return fill(f, input, nil)
 }
 
-func fillStructWithVariant(structValue reflect.Value, variant string) error {
-	structType := structValue.Type()
-	for i := 0; i < structValue.NumField(); i++ {
-		fieldValue := structValue.Field(i)
-		fieldType := structType.Field(i)
-
-		if !fieldValue.CanSet() {
-			continue
-		}
-
-		// Get the appropriate tag value based on variant
-		tagValue := getTagValueForVariant(fieldType, variant)
-
-		// Handle nested structs and pointers
-		if tagValue == TagFill {
-			if err := handleNestedFillWithVariant(fieldValue, fieldType, variant); err != nil {
-				return err
-			}
-			continue
-		}
-
-		// Skip fields without testfill tag
-		if tagValue == "" {
-			continue
-		}
-
-		// Skip non-zero fields
-		if !isZeroValue(fieldValue) {
-			continue
-		}
-
-		if err := setFieldValue(fieldValue, fieldType, tagValue); err != nil {
-			return fmt.Errorf(ErrSetField, fieldType.Name, err)
-		}
+// MustFillJSON is like FillJSON but panics on error.
+// Use this when you are certain the JSON and struct are valid and want to avoid error handling.
+func MustFillJSON[T any](jsonData []byte, opts ...Option) T {
+	result, err := FillJSON[T](jsonData, opts...)
+	if err != nil {
+		panic(err)
 	}
 
-	return nil
+	return result
 }
 
-// =====================================================
-// Reflection utility functions
-// =====================================================
-
-func isZeroValue(v reflect.Value) bool {
-	if !v.IsValid() {
-		return true
+// FillFromCSV builds a T from a CSV header and row, then fills the fields the row left zero
+// based on their testfill tags. Each column sets the field named by a csv tag, or the field of
+// the same name when untagged, converting the value like a tag value. Columns without a
+// matching field, fields tagged csv:"-" and empty values are ignored.
+func FillFromCSV[T any](header []string, row []string) (T, error) {
+	var input T
+	if len(row) != len(header) {
+		return input, fmt.Errorf(ErrCSVRow, len(row), len(header))
 	}
-	return v.IsZero()
-}
 
-// getTagValueForVariant gets the appropriate tag value based on the variant
-// If variant is empty, uses the default "testfill" tag
-// If variant is specified, looks for "testfill_<variant>" tag first, falls back to default
-func getTagValueForVariant(fieldType reflect.StructField, variant string) string {
-	if variant == "" {
-		return fieldType.Tag.Get(TagName)
+	inputValue := reflect.ValueOf(&input).Elem()
+	if inputValue.Kind() != reflect.Struct {
+		return input, fmt.Errorf(ErrNotStruct, input)
 	}
 
-	// Look for variant-specific tag first
-	variantTag := TagName + "_" + variant
-	if value := fieldType.Tag.Get(variantTag); value != "" {
-		return value
+	f := newFiller(nil)
+	if err := f.setCSVColumns(inputValue, header, row); err != nil {
+		return input, err
 	}
 
-	// Fall back to default tag
-	return fieldType.Tag.Get(TagName)
+	return fill(f, input, nil)
 }
 
-// =====================================================
-// Nested struct handling
-// =====================================================
+// FillContext fills the struct like Fill, passing ctx to factory functions whose first
+// parameter is a context.Context. That parameter does not consume a tag argument.
+func FillContext[T any](ctx context.Context, input T) (T, error) {
+	f := newFiller(nil)
+	f.ctx = ctx
+	return fill(f, input, nil)
+}
 
-func handleNestedFillWithVariant(field reflect.Value, fieldType reflect.StructField, variant string) error {
-	switch field.Kind() {
-	case reflect.Struct:
-		if err := fillStructWithVariant(field, variant); err != nil {
-			return fmt.Errorf(ErrNestedStruct, fieldType.Name, err)
-		}
-	case reflect.Ptr:
-		if field.Type().Elem().Kind() == reflect.Struct {
-			if field.IsNil() {
-				// Create new instance if nil
-				newValue := reflect.New(field.Type().Elem())
-				field.Set(newValue)
-			}
-			if err := fillStructWithVariant(field.Elem(), variant); err != nil {
-				return fmt.Errorf(ErrNestedStructPtr, fieldType.Name, err)
-			}
+// FillMany fills each of the given inputs, which may be of different types, and returns the
+// filled copies in the same order. It stops at the first input that fails, reporting its index.
+// The inputs are filled in a single fill call, so rand:unique values are distinct across them.
+func FillMany(inputs ...any) ([]any, error) {
+	f := newFiller(nil)
+	results := make([]any, len(inputs))
+	for i, input := range inputs {
+		result, err := fill(f, input, nil)
+		if err != nil {
+			return nil, fmt.Errorf(ErrFillInput, i, err)
 		}
+		results[i] = result
 	}
-	return nil
+	return results, nil
 }
 
-// =====================================================
-// Field value setting
-// =====================================================
-
-func setFieldValue(field reflect.Value, _ reflect.StructField, tag string) error {
-	// Handle JSON unmarshal
-	if strings.HasPrefix(tag, TagUnmarshal) {
-		jsonData := strings.TrimPrefix(tag, TagUnmarshal)
-		return unmarshalJSON(field, jsonData)
+// FillInPlace fills the value target points to directly, instead of filling and returning a
+// copy like Fill. This saves copying large fixtures in hot loops, but the caller's value is
+// modified, possibly partially when an error is returned. As with Fill, pointers, slices and
+// maps inside the value are shared with whoever else references them.
+func FillInPlace[T any](target *T, opts ...Option) error {
+	if target == nil {
+		return fmt.Errorf(ErrNilTarget, target)
 	}
-
-	// Handle factory functions
-	if strings.HasPrefix(tag, TagFactory) {
-		factoryTag := strings.TrimPrefix(tag, TagFactory)
-		return callFactoryFunction(field, factoryTag)
+	if !isFillableInput(reflect.TypeOf(target).Elem()) {
+		return fmt.Errorf(ErrNotStruct, *target)
 	}
 
-	switch field.Kind() {
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
-		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
-		reflect.Float32, reflect.Float64, reflect.String, reflect.Bool:
-		return setPrimitiveValue(field, tag)
-	case reflect.Slice:
-		return setSliceValue(field, tag)
-	case reflect.Map:
-		return setMapValue(field, tag)
-	case reflect.Ptr:
-		return setPtrValue(field, tag)
-	case reflect.Struct:
-		return setStructValue(field, tag)
-	default:
-		return fmt.Errorf(ErrUnsupportedField, field.Kind())
-	}
+	return newFiller(opts).fillValue(reflect.ValueOf(target).Elem(), nil)
 }
 
-func setSliceValue(field reflect.Value, tag string) error {
-	elemType := field.Type().Elem()
-
-	// Handle struct slices with special "fill:count" syntax
-	if elemType.Kind() == reflect.Struct {
-		return setStructSliceValue(field, tag, elemType)
-	}
-
-	// Handle primitive slices
-	parts := strings.Split(tag, ",")
-	slice := reflect.MakeSlice(field.Type(), len(parts), len(parts))
-
-	for i, part := range parts {
-		elemValue, err := convertStringToType(strings.TrimSpace(part), elemType)
-		if err != nil {
-			return fmt.Errorf(ErrUnsupportedSliceType, elemType.Kind())
-		}
-		slice.Index(i).Set(elemValue)
+// ZeroThenFill resets the tagged fields of a struct to their tag values: it zeroes every field
+// carrying a testfill tag, ignoring the values already set, and then fills it like Fill. This
+// normalizes reused fixtures to their canonical defaults. Fields tagged "-" or required and
+// untagged fields keep their values, including inside nested fill structs. The caller's
+// nested structs are copied, not modified.
+func ZeroThenFill[T any](input T) (T, error) {
+	value := reflect.ValueOf(&input).Elem()
+	if value.Kind() == reflect.Struct {
+		value.Set(deepCopy(value, make(map[uintptr]reflect.Value)))
+		zeroTaggedFields(value)
 	}
-
-	field.Set(slice)
-	return nil
+	return Fill(input)
 }
 
-func setStructSliceValue(field reflect.Value, tag string, elemType reflect.Type) error {
-	// Support "fill:count" syntax for struct slices
-	if strings.HasPrefix(tag, "fill:") {
-		countStr := strings.TrimPrefix(tag, "fill:")
-		count, err := strconv.Atoi(countStr)
-		if err != nil {
-			return fmt.Errorf("invalid slice count format: %s", tag)
+func zeroTaggedFields(structValue reflect.Value) {
+	for i := 0; i < structValue.NumField(); i++ {
+		field := structValue.Field(i)
+		tag := structValue.Type().Field(i).Tag.Get(TagName)
+		if !field.CanSet() || tag == "" || tag == TagIgnore || tag == TagRequired {
+			continue
 		}
 
-		slice := reflect.MakeSlice(field.Type(), count, count)
-		for i := 0; i < count; i++ {
-			elemValue := reflect.New(elemType).Elem()
-			if err := fillStruct(elemValue); err != nil {
-				return fmt.Errorf("failed to fill slice element %d: %w", i, err)
+		// Nested fill structs are reset field by field, keeping their untagged fields
+		if tag == TagFill || tag == TagShallow || strings.HasPrefix(tag, TagPinned) {
+			if field.Kind() == reflect.Ptr && field.Type().Elem().Kind() == reflect.Struct && !field.IsNil() {
+				zeroTaggedFields(field.Elem())
+				continue
+			}
+			if field.Kind() == reflect.Struct {
+				zeroTaggedFields(field)
+				continue
 			}
-			slice.Index(i).Set(elemValue)
 		}
-		field.Set(slice)
-		return nil
+		field.Set(reflect.Zero(field.Type()))
 	}
+}
 
-	// Support "variants:name1,name2,name3" syntax for struct slices with different field values
-	if strings.HasPrefix(tag, TagVariant) {
-		variantStr := strings.TrimPrefix(tag, TagVariant)
-		variants := strings.Split(variantStr, ",")
-
-		// Clean up variant names
-		for i, variant := range variants {
-			variants[i] = strings.TrimSpace(variant)
-		}
+// FillWithOptions is like Fill but accepts options that adjust the filling behavior.
+// Without options it behaves exactly like Fill.
+func FillWithOptions[T any](input T, opts ...Option) (T, error) {
+	return fill(newFiller(opts), input, nil)
+}
 
-		slice := reflect.MakeSlice(field.Type(), len(variants), len(variants))
-		for i, variant := range variants {
-			elemValue := reflect.New(elemType).Elem()
-			if err := fillStructWithVariant(elemValue, variant); err != nil {
-				return fmt.Errorf("failed to fill slice element %d with variant %s: %w", i, variant, err)
-			}
-			slice.Index(i).Set(elemValue)
-		}
-		field.Set(slice)
-		return nil
+// MustFillWithOptions is like FillWithOptions but panics on error.
+// Use this when you are certain the struct is valid and want to avoid error handling.
+func MustFillWithOptions[T any](input T, opts ...Option) T {
+	result, err := FillWithOptions(input, opts...)
+	if err != nil {
+		panic(err)
 	}
 
-	return fmt.Errorf(ErrUnsupportedSliceType, elemType.Kind())
+	return result
 }
 
-func setMapValue(field reflect.Value, tag string) error {
-	keyType := field.Type().Key()
-	valueType := field.Type().Elem()
+// Builder sets a few fields of a T in code and fills the rest from their tags, for tests that
+// tweak one or two fields of an otherwise default fixture.
+//
+//	user, err := testfill.NewBuilder[User]().With(func(u *User) { u.Role = "admin" }).Fill()
+type Builder[T any] struct {
+	setters  []func(*T)
+	opts     []Option
+	variants []string
+}
 
-	// Handle struct value maps with special "key:fill" syntax
-	if valueType.Kind() == reflect.Struct {
-		return setStructMapValue(field, tag, keyType, valueType)
-	}
+// NewBuilder creates a Builder starting from the zero value of T.
+func NewBuilder[T any]() *Builder[T] {
+	return &Builder[T]{}
+}
 
-	// Handle primitive maps
-	m := reflect.MakeMap(field.Type())
-	pairs := strings.Split(tag, ",")
+// With adds a function setting fields in code. Setters run in order before the fill, so the
+// fields they set are kept.
+func (b *Builder[T]) With(set func(*T)) *Builder[T] {
+	b.setters = append(b.setters, set)
+	return b
+}
 
-	for _, pair := range pairs {
-		kv := strings.Split(strings.TrimSpace(pair), ":")
-		if len(kv) != 2 {
-			return fmt.Errorf(ErrInvalidMapFormat, pair)
-		}
+// WithOptions adds options applied by Fill.
+func (b *Builder[T]) WithOptions(opts ...Option) *Builder[T] {
+	b.opts = append(b.opts, opts...)
+	return b
+}
 
-		keyValue, err := convertStringToType(strings.TrimSpace(kv[0]), keyType)
-		if err != nil {
-			return fmt.Errorf(ErrUnsupportedMapType, keyType.Kind(), valueType.Kind())
-		}
+// WithVariants fills with the given variants, in priority order as in FillWithVariants.
+func (b *Builder[T]) WithVariants(variants ...string) *Builder[T] {
+	b.variants = append(b.variants, variants...)
+	return b
+}
 
-		valueValue, err := convertStringToType(strings.TrimSpace(kv[1]), valueType)
-		if err != nil {
-			return fmt.Errorf(ErrUnsupportedMapType, keyType.Kind(), valueType.Kind())
-		}
+// Fill builds a new T: it applies the setters to a zero value and fills the fields they left
+// zero. Each call builds a separate value, so a Builder can be reused.
+func (b *Builder[T]) Fill() (T, error) {
+	var value T
+	for _, set := range b.setters {
+		set(&value)
+	}
+	return fill(newFiller(b.opts), value, b.variants)
+}
 
-		m.SetMapIndex(keyValue, valueValue)
+// MustFill is like Fill but panics on error.
+func (b *Builder[T]) MustFill() T {
+	result, err := b.Fill()
+	if err != nil {
+		panic(err)
 	}
 
-	field.Set(m)
-	return nil
+	return result
 }
 
-func setStructMapValue(field reflect.Value, tag string, keyType, valueType reflect.Type) error {
-	// Only support string keys for struct value maps
-	if keyType.Kind() != reflect.String {
-		return fmt.Errorf(ErrUnsupportedMapType, keyType.Kind(), valueType.Kind())
+// Plan reports what Fill would do with each field of input without filling anything.
+// Every visited field yields a FillAction holding its path, the tag used, and either the
+// value it would be filled with or the reason it would be skipped. Plan works on a deep copy
+// of input, which may also be a pointer to a struct, and uses its own incr counters, so
+// neither the caller's data nor the package-wide counters change.
+func Plan[T any](input T) ([]FillAction, error) {
+	value := deepCopy(reflect.ValueOf(input), make(map[uintptr]reflect.Value))
+	if value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	if !value.IsValid() || !isFillableInput(value.Type()) {
+		return nil, fmt.Errorf(ErrNotStruct, input)
 	}
 
-	// Check if this is a variants syntax
-	if strings.HasPrefix(tag, "variants:") {
-		return setStructMapWithVariants(field, tag, valueType)
+	actions := []FillAction{}
+	f := newFiller([]Option{WithCounters(NewCounters())})
+	f.actions = &actions
+	resultValue := reflect.New(value.Type()).Elem()
+	resultValue.Set(value)
+	if err := f.fillValue(resultValue, nil); err != nil {
+		return nil, err
 	}
 
-	m := reflect.MakeMap(field.Type())
-	pairs := strings.Split(tag, ",")
+	return actions, nil
+}
 
-	for _, pair := range pairs {
-		kv := strings.Split(strings.TrimSpace(pair), ":")
-		if len(kv) != 2 {
-			return fmt.Errorf(ErrInvalidMapFormat, pair)
+// deepCopy returns a copy of v that shares no pointers, slices or maps with it, so filling
+// the copy leaves v untouched. copies maps the pointers already copied, to preserve cycles.
+// Unexported fields are copied shallowly.
+func deepCopy(v reflect.Value, copies map[uintptr]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
 		}
-
-		keyStr := strings.TrimSpace(kv[0])
-		valueStr := strings.TrimSpace(kv[1])
-
-		keyValue := reflect.ValueOf(keyStr)
-
-		if valueStr == "fill" {
-			// Create and fill a new struct instance with default variant
-			structValue := reflect.New(valueType).Elem()
-			if err := fillStructWithVariant(structValue, ""); err != nil {
-				return fmt.Errorf("failed to fill map value for key %s: %w", keyStr, err)
-			}
-			m.SetMapIndex(keyValue, structValue)
-		} else {
-			// Assume valueStr is a variant name
-			structValue := reflect.New(valueType).Elem()
-			if err := fillStructWithVariant(structValue, valueStr); err != nil {
-				return fmt.Errorf("failed to fill map value for key %s with variant %s: %w", keyStr, valueStr, err)
+		if c, exists := copies[v.Pointer()]; exists {
+			return c
+		}
+		c := reflect.New(v.Type().Elem())
+		copies[v.Pointer()] = c
+		c.Elem().Set(deepCopy(v.Elem(), copies))
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < c.NumField(); i++ {
+			if c.Field(i).CanSet() {
+				c.Field(i).Set(deepCopy(v.Field(i), copies))
 			}
-			m.SetMapIndex(keyValue, structValue)
 		}
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i), copies))
+		}
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i), copies))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), deepCopy(iter.Value(), copies))
+		}
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopy(v.Elem(), copies))
+		return c
 	}
-
-	field.Set(m)
-	return nil
+	return v
 }
 
-func setStructMapWithVariants(field reflect.Value, tag string, valueType reflect.Type) error {
-	// Extract variants from "variants:key1=variant1,key2=variant2,..." syntax
-	variantStr := strings.TrimPrefix(tag, "variants:")
-	items := strings.Split(variantStr, ",")
-
-	// Clean up items
-	for i, item := range items {
-		items[i] = strings.TrimSpace(item)
+// FillWithReport is like Fill but also returns a Report listing the paths of the fields
+// that were filled and of those that were skipped.
+func FillWithReport[T any](input T) (T, Report, error) {
+	actions := []FillAction{}
+	f := newFiller(nil)
+	f.actions = &actions
+	result, err := fill(f, input, nil)
+	if err != nil {
+		return result, Report{}, err
 	}
 
-	m := reflect.MakeMap(field.Type())
+	return result, newReport(actions), nil
+}
 
-	for _, item := range items {
-		// All items must use key=variant syntax
-		kv := strings.Split(item, "=")
-		if len(kv) != 2 {
-			return fmt.Errorf("invalid key=variant format: %s (expected format: key=variant)", item)
-		}
+// RegisterFactory registers a factory function that can be called from struct tags.
+// The function must return exactly one value that matches the field type. A function returning
+// an interface such as interface{} is also accepted when its dynamic value matches the field type.
+// Factory functions can accept string arguments that will be converted to the appropriate types.
+//
+// Example:
+//	// Register a factory function
+//	testfill.RegisterFactory("uuid", func() string { return "test-uuid-123" })
+//	
+//	// Use in struct tag
+//	type User struct {
+//		ID string `testfill:"factory:uuid"`
+//	}
+//
+// RegisterFactory is safe for concurrent use with Fill and other registrations.
+func RegisterFactory(name string, fn interface{}) {
+	factoryMu.Lock()
+	defer factoryMu.Unlock()
+	funcValue := reflect.ValueOf(fn)
+	if !funcValue.IsValid() {
+		delete(factoryRegistry, name)
+		return
+	}
+	factoryRegistry[name] = registeredFactory{value: funcValue, funcType: funcValue.Type()}
+}
 
-		keyStr := strings.TrimSpace(kv[0])
+// UnregisterFactory removes the factory function registered under name, if any.
+func UnregisterFactory(name string) {
+	factoryMu.Lock()
+	defer factoryMu.Unlock()
+	delete(factoryRegistry, name)
+}
+
+// RegisterZeroChecker registers a function that decides whether values of type t are empty.
+// Fill consults it instead of reflect.Value.IsZero, so value objects that are logically empty
+// without being Go zero values can still be filled from their tags.
+func RegisterZeroChecker(t reflect.Type, fn func(reflect.Value) bool) {
+	zeroCheckerMu.Lock()
+	defer zeroCheckerMu.Unlock()
+	zeroCheckerRegistry[t] = fn
+}
+
+// RegisterConverter registers a function that converts tag values to type t. Registered
+// converters take precedence over the built-in conversions and encoding.TextUnmarshaler, and
+// also apply to slice elements, map keys and values, and factory arguments of type t.
+//
+// Example:
+//
+//	testfill.RegisterConverter(reflect.TypeOf(decimal.Decimal{}), func(s string) (interface{}, error) {
+//		return decimal.NewFromString(s)
+//	})
+func RegisterConverter(t reflect.Type, fn func(string) (interface{}, error)) {
+	converterMu.Lock()
+	defer converterMu.Unlock()
+	converterRegistry[t] = fn
+}
+
+// RegisterPostFill registers a hook that runs after every struct of type t has been filled,
+// including nested occurrences, once all of its fields are set. It can compute derived fields
+// or validate the result; an error aborts the fill and is reported with the struct's path.
+func RegisterPostFill(t reflect.Type, fn func(reflect.Value) error) {
+	postFillMu.Lock()
+	defer postFillMu.Unlock()
+	postFillRegistry[t] = fn
+}
+
+// RegisterFlags registers the names of the bit flags of the integer type t, so fields of that
+// type can be filled with testfill:"flags:Read|Write", which ORs the named values together.
+//
+// Example:
+//
+//	testfill.RegisterFlags(reflect.TypeOf(Perm(0)), map[string]uint64{
+//		"Read": uint64(Read), "Write": uint64(Write), "Exec": uint64(Exec),
+//	})
+func RegisterFlags(t reflect.Type, flags map[string]uint64) {
+	flagMu.Lock()
+	defer flagMu.Unlock()
+	flagRegistry[t] = flags
+}
+
+// RegisterImplementation registers the concrete type used to fill interface fields and
+// interface-element slices tagged with fill, fill:N or variants:. The implementation must be
+// a struct or a pointer to a struct that implements iface; it is filled from its own tags.
+//
+// Example:
+//
+//	testfill.RegisterImplementation(reflect.TypeOf((*Shape)(nil)).Elem(), reflect.TypeOf(&Circle{}))
+//
+//	type Drawing struct {
+//		Shapes []Shape `testfill:"fill:3"`
+//	}
+func RegisterImplementation(iface reflect.Type, impl reflect.Type) {
+	implementationMu.Lock()
+	defer implementationMu.Unlock()
+	implementationRegistry[iface] = impl
+}
+
+// RegisterProvider registers the function that resolves provider:key tags at fill time,
+// for values such as secrets or generated data that cannot be written in the tag itself.
+// The returned string is converted to the field type like any other tag value.
+//
+// Example:
+//
+//	testfill.RegisterProvider(func(key string) (string, error) { return os.Getenv(key), nil })
+//
+//	type Client struct {
+//		Token string `testfill:"provider:API_TOKEN"`
+//	}
+func RegisterProvider(fn func(key string) (string, error)) {
+	providerMu.Lock()
+	defer providerMu.Unlock()
+	valueProvider = fn
+}
+
+// RegisterObject registers a prebuilt object that fields can share with testfill:"ref:name".
+// The object is assigned as is, so registering a pointer makes every referencing fixture point to
+// the same instance. Registering nil removes the object.
+//
+// Example:
+//
+//	testfill.RegisterObject("defaultTenant", &Tenant{ID: "acme"})
+//
+//	type User struct {
+//		Tenant *Tenant `testfill:"ref:defaultTenant"`
+//	}
+func RegisterObject(name string, object interface{}) {
+	objectMu.Lock()
+	defer objectMu.Unlock()
+	value := reflect.ValueOf(object)
+	if !value.IsValid() {
+		delete(objectRegistry, name)
+		return
+	}
+	objectRegistry[name] = value
+}
+
+// RegisterMacro registers a named tag that fields can use as testfill:"@name", to avoid repeating
+// long tags across structs. The macro text is substituted for the whole tag value before it is
+// interpreted, in every variant tag.
+//
+// Example:
+//
+//	testfill.RegisterMacro("settings", `unmarshal:{"theme":"dark","lang":"en"}`)
+//
+//	type User struct {
+//		Settings map[string]string `testfill:"@settings"`
+//	}
+func RegisterMacro(name string, tag string) {
+	macroMu.Lock()
+	defer macroMu.Unlock()
+	macroRegistry[name] = tag
+}
+
+// ParseTag parses a testfill tag value into its directive, the way Fill interprets it.
+// Tags that are not directives, such as "42" or "a,b,c", are returned as DirectiveLiteral.
+// The directive arguments are split but not validated against any field type.
+func ParseTag(tag string) (Directive, error) {
+	return parseDirective(tag)
+}
+
+// Pair is a key/value pair parsed from a map tag.
+type Pair struct {
+	Key   string
+	Value string
+}
+
+// FillOrderedPairs parses a map tag such as "b:2,a:1" into its key/value pairs in tag order,
+// the same way map fields are filled. Since Go maps are unordered, this lets callers build
+// ordered structures from the same tag syntax.
+func FillOrderedPairs(tag string) ([]Pair, error) {
+	return parsePairs(tag, 0)
+}
+
+// =====================================================
+// Fill plan
+// =====================================================
+
+// Reasons reported in FillAction.Skipped
+const (
+	SkipNoTag    = "no testfill tag"
+	SkipNonZero  = "field is not zero"
+	SkipSyncType = "field is a synchronization primitive"
+	SkipWhen     = "when condition not met"
+	SkipShallow  = "inside a fill:shallow struct"
+	SkipPath     = "path skipped by WithSkip"
+	SkipNotOnly  = "path not selected by WithOnly"
+	SkipBlank    = "tagged blank"
+	SkipIgnored  = "tagged -"
+)
+
+// FillAction describes what Fill does with a single field.
+type FillAction struct {
+	// Path is the dotted path of the field, e.g. "Users[2].Address.City".
+	Path string
+	// Tag is the testfill tag value that applies to the field, if any.
+	Tag string
+	// Value is the string form of the value the field is filled with, or of its current value when skipped.
+	Value string
+	// Skipped holds the reason the field is not filled, or is empty when it is filled.
+	Skipped string
+}
+
+// Report summarizes the outcome of a fill call by field path.
+// The number of fields in each category is the length of the corresponding list.
+type Report struct {
+	// Filled lists the fields that were filled from their tags.
+	Filled []string
+	// SkippedNonZero lists the tagged fields that were left untouched because they were not zero.
+	SkippedNonZero []string
+	// SkippedNoTag lists the fields that were left untouched because they have no testfill tag.
+	SkippedNoTag []string
+}
+
+func newReport(actions []FillAction) Report {
+	var report Report
+	for _, action := range actions {
+		switch action.Skipped {
+		case "":
+			report.Filled = append(report.Filled, action.Path)
+		case SkipNonZero:
+			report.SkippedNonZero = append(report.SkippedNonZero, action.Path)
+		case SkipNoTag:
+			report.SkippedNoTag = append(report.SkippedNoTag, action.Path)
+		}
+	}
+	return report
+}
+
+func (f *filler) recordAction(tag string, fieldValue reflect.Value, skipped string) {
+	if f.actions == nil {
+		return
+	}
+
+	*f.actions = append(*f.actions, FillAction{
+		Path:    f.currentPath(),
+		Tag:     tag,
+		Value:   formatValue(fieldValue),
+		Skipped: skipped,
+	})
+}
+
+// formatValue renders a value for display, following non-nil pointers.
+func formatValue(v reflect.Value) string {
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// =====================================================
+// Options
+// =====================================================
+
+// Option configures optional behavior of FillWithOptions.
+type Option func(*options)
+
+type options struct {
+	autoFillEmbedded      bool
+	disallowUnknownFields bool
+	skipEmptyPointers     bool
+	strictTags            bool
+	templateData          map[string]any
+	passFactoryPanics     bool
+	overrideJSON          []byte
+	strictBool            bool
+	factoryDefaults       bool
+	skipPaths             []string
+	onlyPaths             []string
+	seed                  int64
+	seeded                bool
+	ignoreExtraReturns    bool
+	convertibleReturns    bool
+	now                   func() time.Time
+	randSource            rand.Source
+	counters              *Counters
+	timeLayouts           []string
+	maxSliceCount         int
+	warnUnexportedTags    bool
+	fieldHook             func(path string, t reflect.Type) (reflect.Value, bool, error)
+}
+
+// WithAutoFillEmbedded makes embedded (anonymous) struct fields be filled recursively
+// even when they carry no fill tag, as if they were tagged with testfill:"fill".
+func WithAutoFillEmbedded(enabled bool) Option {
+	return func(o *options) {
+		o.autoFillEmbedded = enabled
+	}
+}
+
+// WithDisallowUnknownFields makes JSON unmarshaling (unmarshal: tags, testfill_json tags and
+// FillJSON) fail on object keys that do not match any field, instead of silently dropping them.
+func WithDisallowUnknownFields(enabled bool) Option {
+	return func(o *options) {
+		o.disallowUnknownFields = enabled
+	}
+}
+
+// WithSkipEmptyPointers leaves nil struct pointers tagged with fill as nil when none of the
+// pointed struct's fields carry a testfill tag, instead of allocating an empty struct.
+func WithSkipEmptyPointers(enabled bool) Option {
+	return func(o *options) {
+		o.skipEmptyPointers = enabled
+	}
+}
+
+// WithStrictTags rejects tag values that look like a directive (a word followed by a colon)
+// but do not name a known one, such as a mistyped "factroy:New", instead of treating them as
+// literal values. Map tags such as "rate:5" are only rejected when they fail to convert.
+func WithStrictTags(enabled bool) Option {
+	return func(o *options) {
+		o.strictTags = enabled
+	}
+}
+
+// WithTemplateData sets the data that tmpl: tags are executed against with text/template,
+// e.g. testfill:"tmpl:{{.Env}}-service". Referencing a key missing from data is an error.
+func WithTemplateData(data map[string]any) Option {
+	return func(o *options) {
+		o.templateData = data
+	}
+}
+
+// WithFactoryPanicPassthrough lets panics in factory functions propagate with their original
+// stack trace instead of being recovered into an error, which helps debugging a factory.
+func WithFactoryPanicPassthrough(enabled bool) Option {
+	return func(o *options) {
+		o.passFactoryPanics = enabled
+	}
+}
+
+// WithOverrideJSON merges sparse JSON onto the result once it is filled, so a fixture built from
+// tag defaults can be tweaked in one deep place. Only the fields present in the JSON change.
+func WithOverrideJSON(data []byte) Option {
+	return func(o *options) {
+		o.overrideJSON = data
+	}
+}
+
+// WithFactoryDefaults lets factory tags omit trailing arguments, which are passed as the zero
+// value of their parameter types. By default the argument count must match exactly.
+func WithFactoryDefaults(enabled bool) Option {
+	return func(o *options) {
+		o.factoryDefaults = enabled
+	}
+}
+
+// WithFactoryIgnoreExtraReturns lets factories return more than one value, such as (T, bool),
+// using the first one and ignoring the rest. A non-nil trailing error, as in (T, error), still
+// fails the fill. By default factories must return exactly one value.
+func WithFactoryIgnoreExtraReturns(enabled bool) Option {
+	return func(o *options) {
+		o.ignoreExtraReturns = enabled
+	}
+}
+
+// WithConvertibleFactoryReturns lets factories return a type convertible to the field type,
+// such as a named int for an int field, converting the result. By default the result must be
+// assignable to the field.
+func WithConvertibleFactoryReturns(enabled bool) Option {
+	return func(o *options) {
+		o.convertibleReturns = enabled
+	}
+}
+
+// WithSkip leaves the fields at the given dotted paths, such as "Address.City" or "Secrets", at
+// their current value regardless of their tags. Paths match either exactly, including slice
+// indexes and map keys ("Users[0].Name"), or with them left out ("Users.Name").
+func WithSkip(paths ...string) Option {
+	return func(o *options) {
+		o.skipPaths = append(o.skipPaths, paths...)
+	}
+}
+
+// WithOnly fills only the fields at the given dotted paths and the fields nested inside them,
+// leaving every other field untouched. The structs and slices leading to a listed path are
+// still filled as far as needed to reach it. Paths match like those of WithSkip.
+func WithOnly(paths ...string) Option {
+	return func(o *options) {
+		o.onlyPaths = append(o.onlyPaths, paths...)
+	}
+}
+
+// WithSeed seeds the random generator behind rand: and uuid tags, so that a fill call produces
+// the same values on every run. Without it each fill call uses a time-based seed.
+func WithSeed(seed int64) Option {
+	return func(o *options) {
+		o.seed = seed
+		o.seeded = true
+	}
+}
+
+// WithNow sets the clock read by now tags, so that time-dependent fixtures are deterministic.
+// Without it now is the current time.
+func WithNow(now func() time.Time) Option {
+	return func(o *options) {
+		o.now = now
+	}
+}
+
+// WithRandSource sets the source of the random generator behind rand: and uuid tags, taking
+// precedence over WithSeed. Sources are not safe for concurrent use, so concurrent fill calls
+// should not share one.
+func WithRandSource(source rand.Source) Option {
+	return func(o *options) {
+		o.randSource = source
+	}
+}
+
+// WithCounters makes incr tags read their sequences from counters instead of the package
+// counters shared by every fill call.
+func WithCounters(counters *Counters) Option {
+	return func(o *options) {
+		o.counters = counters
+	}
+}
+
+// WithTimeLayouts sets the layouts time.Time tags are parsed with, tried in order. The default
+// is time.RFC3339 alone; include it in the list to keep accepting RFC3339 values.
+func WithTimeLayouts(layouts ...string) Option {
+	return func(o *options) {
+		o.timeLayouts = append(o.timeLayouts, layouts...)
+	}
+}
+
+// WithMaxSliceCount rejects fill:N, repeat:N:value, range and dates tags asking for more than
+// n elements, guarding against huge allocations from a mistyped count or bound. By default
+// counts are only limited for range tags, to 16,777,216 values.
+func WithMaxSliceCount(n int) Option {
+	return func(o *options) {
+		o.maxSliceCount = n
+	}
+}
+
+// WithWarnUnexportedTags makes fill calls fail on unexported fields carrying a testfill tag,
+// which cannot be filled, instead of silently skipping them.
+func WithWarnUnexportedTags(enabled bool) Option {
+	return func(o *options) {
+		o.warnUnexportedTags = enabled
+	}
+}
+
+// WithFieldHook registers a callback invoked with the path (e.g. "Users[2].Address.City") and
+// type of every zero field before its tag is read, tagged or not. When it returns ok, the
+// returned value is assigned and the tag is ignored; otherwise the field is filled as usual.
+// An error aborts the fill and is reported with the field path.
+func WithFieldHook(hook func(path string, t reflect.Type) (reflect.Value, bool, error)) Option {
+	return func(o *options) {
+		o.fieldHook = hook
+	}
+}
+
+// WithStrictBool restricts bool tags to the values accepted by strconv.ParseBool, rejecting
+// aliases such as "yes", "off" or "N".
+func WithStrictBool(enabled bool) Option {
+	return func(o *options) {
+		o.strictBool = enabled
+	}
+}
+
+// =====================================================
+// Core struct filling logic
+// =====================================================
+
+// filler carries the options and state of a single fill call through the recursive traversal.
+type filler struct {
+	opts         options
+	ctx          context.Context
+	path         []string
+	elementIndex int
+	actions      *[]FillAction
+	rand         *rand.Rand
+	uniqueValues map[string]bool
+	// shallow is set while filling a fill:shallow struct, whose fill children are skipped
+	shallow bool
+}
+
+func newFiller(opts []Option) *filler {
+	f := &filler{ctx: context.Background()}
+	for _, opt := range opts {
+		opt(&f.opts)
+	}
+	return f
+}
+
+func fill[T any](f *filler, input T, variants []string) (T, error) {
+	var zero T
+	inputValue := reflect.ValueOf(input)
+	inputType := reflect.TypeOf(input)
+
+	if !isFillableInput(inputType) {
+		return zero, fmt.Errorf(ErrNotStruct, input)
+	}
+
+	// Create a copy to work with
+	resultValue := reflect.New(inputType).Elem()
+	resultValue.Set(inputValue)
+
+	if err := f.fillValue(resultValue, variants); err != nil {
+		return zero, err
+	}
+	return resultValue.Interface().(T), nil
+}
+
+// fillValue fills an addressable struct, or slice or map of structs, in place.
+func (f *filler) fillValue(resultValue reflect.Value, variants []string) error {
+	var err error
+	switch resultValue.Kind() {
+	case reflect.Slice:
+		err = f.fillSliceElements(resultValue, variants)
+	case reflect.Map:
+		err = f.fillMapValues(resultValue, variants)
+	default:
+		err = f.fillStructWithVariant(resultValue, variants)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Merge the override JSON on top of the filled result
+	if len(f.opts.overrideJSON) > 0 {
+		if err := f.decodeJSON(resultValue.Addr().Interface(), string(f.opts.overrideJSON)); err != nil {
+			return fmt.Errorf(ErrOverrideJSON, err)
+		}
+	}
+	return nil
+}
+
+// isFillableInput reports whether t is a struct, or a slice or map of structs.
+func isFillableInput(t reflect.Type) bool {
+	if t == nil {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		return true
+	case reflect.Slice, reflect.Map:
+		return t.Elem().Kind() == reflect.Struct
+	}
+	return false
+}
+
+// fillSliceElements replaces the slice with a copy whose struct elements are filled.
+func (f *filler) fillSliceElements(slice reflect.Value, variants []string) error {
+	if slice.IsNil() {
+		return nil
+	}
+
+	filled := reflect.MakeSlice(slice.Type(), slice.Len(), slice.Len())
+	reflect.Copy(filled, slice)
+	for i := 0; i < filled.Len(); i++ {
+		if err := f.fillIndexedElement(i, filled.Index(i), variants); err != nil {
+			return fmt.Errorf(ErrFillElement, i, err)
+		}
+	}
+
+	slice.Set(filled)
+	return nil
+}
+
+// fillMapValues replaces the map with a copy whose struct values are filled.
+func (f *filler) fillMapValues(m reflect.Value, variants []string) error {
+	if m.IsNil() {
+		return nil
+	}
+
+	filled := reflect.MakeMapWithSize(m.Type(), m.Len())
+	iter := m.MapRange()
+	for iter.Next() {
+		value := reflect.New(m.Type().Elem()).Elem()
+		value.Set(iter.Value())
+		if err := f.fillElement(keySegment(iter.Key()), value, variants); err != nil {
+			return fmt.Errorf(ErrFillMapValue, iter.Key(), err)
+		}
+		filled.SetMapIndex(iter.Key(), value)
+	}
+
+	m.Set(filled)
+	return nil
+}
+
+func (f *filler) fillStructWithVariant(structValue reflect.Value, variants []string) error {
+	structType := structValue.Type()
+	f.applyDefaults(structValue)
+
+	// Fields whose tags reference sibling fields are filled after the others,
+	// and fields with a when: condition after those. Required fields are checked last.
+	references := make(map[string]string)
+	var referenceOrder []string
+	var conditionals []reflect.StructField
+	var required []reflect.StructField
+
+	for i := 0; i < structValue.NumField(); i++ {
+		fieldValue := structValue.Field(i)
+		fieldType := structType.Field(i)
+
+		if !fieldValue.CanSet() {
+			ignored := fieldType.Tag.Get(TagName) == TagIgnore
+			if f.opts.warnUnexportedTags && !ignored && getTagValueForVariant(fieldType, variants) != "" {
+				return f.fieldError(fieldType.Name, errors.New(ErrUnexportedTag))
+			}
+			continue
+		}
+
+		// Like encoding/json, fields tagged "-" are ignored, whatever the variant
+		if fieldType.Tag.Get(TagName) == TagIgnore {
+			f.enterPath(fieldType.Name)
+			f.recordAction(TagIgnore, fieldValue, SkipIgnored)
+			f.leavePath()
+			continue
+		}
+
+		if f.opts.fieldHook != nil && isZeroValue(fieldValue) {
+			handled, err := f.applyFieldHook(fieldValue, fieldType)
+			if err != nil {
+				return err
+			}
+			if handled {
+				continue
+			}
+		}
+
+		// Get the appropriate tag value based on variant, stripping its comment before any
+		// ${Field} reference is expanded so referenced values are never cut
+		tagValue, err := expandMacro(getTagValueForVariant(fieldType, variants))
+		if err != nil {
+			return f.fieldError(fieldType.Name, err)
+		}
+		tagValue = stripComment(tagValue)
+
+		if strings.HasPrefix(tagValue, TagWhen) {
+			conditionals = append(conditionals, fieldType)
+			continue
+		}
+
+		if tagValue == TagRequired {
+			required = append(required, fieldType)
+			continue
+		}
+
+		if hasFieldReferences(tagValue) || strings.HasPrefix(tagValue, TagCopy) || strings.HasPrefix(tagValue, TagCountOf) {
+			references[fieldType.Name] = tagValue
+			referenceOrder = append(referenceOrder, fieldType.Name)
+			continue
+		}
+
+		if err := f.fillField(fieldValue, fieldType, tagValue, variants); err != nil {
+			return err
+		}
+	}
+
+	if err := f.fillReferencingFields(structValue, references, referenceOrder, variants); err != nil {
+		return err
+	}
+	if err := f.fillConditionalFields(structValue, conditionals, variants); err != nil {
+		return err
+	}
+	if err := f.checkRequiredFields(structValue, required); err != nil {
+		return err
+	}
+	return f.runPostFill(structValue)
+}
+
+// applyFieldHook lets the WithFieldHook callback fill a field, reporting whether it did.
+// Fields excluded with WithSkip or WithOnly are left to fillField, which records why.
+func (f *filler) applyFieldHook(fieldValue reflect.Value, fieldType reflect.StructField) (bool, error) {
+	f.enterPath(fieldType.Name)
+	defer f.leavePath()
+
+	if f.isSkippedPath() || !f.isSelectedPath() {
+		return false, nil
+	}
+
+	value, ok, err := f.opts.fieldHook(f.currentPath(), fieldType.Type)
+	if err != nil {
+		return false, f.newFieldError(err)
+	}
+	if !ok {
+		return false, nil
+	}
+	if !value.IsValid() {
+		return false, f.newFieldError(fmt.Errorf(ErrFieldHookType, "no value", fieldType.Type))
+	}
+	if !value.Type().AssignableTo(fieldType.Type) {
+		return false, f.newFieldError(fmt.Errorf(ErrFieldHookType, value.Type(), fieldType.Type))
+	}
+
+	fieldValue.Set(value)
+	f.recordAction(fieldType.Tag.Get(TagName), fieldValue, "")
+	return true, nil
+}
+
+// checkRequiredFields fails when a field tagged required was not set by the caller.
+func (f *filler) checkRequiredFields(structValue reflect.Value, required []reflect.StructField) error {
+	for _, fieldType := range required {
+		fieldValue := structValue.FieldByIndex(fieldType.Index)
+		if isZeroValue(fieldValue) {
+			return f.fieldError(fieldType.Name, errors.New(ErrRequired))
+		}
+
+		f.enterPath(fieldType.Name)
+		f.recordAction(TagRequired, fieldValue, SkipNonZero)
+		f.leavePath()
+	}
+	return nil
+}
+
+// Post-fill hook registry, guarded by postFillMu
+var (
+	postFillMu       sync.RWMutex
+	postFillRegistry = make(map[reflect.Type]func(reflect.Value) error)
+)
+
+func getPostFillHook(t reflect.Type) (func(reflect.Value) error, bool) {
+	postFillMu.RLock()
+	defer postFillMu.RUnlock()
+	hook, exists := postFillRegistry[t]
+	return hook, exists
+}
+
+// runPostFill runs the post-fill hook registered for the type of a filled struct.
+func (f *filler) runPostFill(structValue reflect.Value) error {
+	hook, exists := getPostFillHook(structValue.Type())
+	if !exists {
+		return nil
+	}
+
+	if err := hook(structValue); err != nil {
+		if len(f.path) == 0 {
+			return fmt.Errorf(ErrPostFillStruct, structValue.Type(), err)
+		}
+		return f.newFieldError(fmt.Errorf(ErrPostFill, structValue.Type(), err))
+	}
+	return nil
+}
+
+func (f *filler) fillField(fieldValue reflect.Value, fieldType reflect.StructField, tagValue string, variants []string) error {
+	f.enterPath(fieldType.Name)
+	defer f.leavePath()
+
+	if f.isSkippedPath() {
+		f.recordAction(tagValue, fieldValue, SkipPath)
+		return nil
+	}
+	if !f.isSelectedPath() {
+		f.recordAction(tagValue, fieldValue, SkipNotOnly)
+		return nil
+	}
+
+	// Never touch synchronization primitives, even when tagged
+	if isSyncType(fieldType.Type) {
+		f.recordAction(tagValue, fieldValue, SkipSyncType)
+		return nil
+	}
+
+	// Fields tagged blank stay as they are, even inside filled structs
+	if tagValue == TagBlank {
+		f.recordAction(tagValue, fieldValue, SkipBlank)
+		return nil
+	}
+
+	// Embedded structs are treated as if tagged with fill when requested
+	if tagValue == "" && fieldType.Anonymous && f.opts.autoFillEmbedded {
+		tagValue = TagFill
+	}
+
+	// Handle nested structs and pointers
+	if isNestedFillTag(tagValue) {
+		if f.shallow {
+			f.recordAction(tagValue, fieldValue, SkipShallow)
+			return nil
+		}
+		return f.handleNestedFillWithVariant(fieldValue, fieldType, tagValue, variants)
+	}
+
+	// Skip fields without testfill tag
+	if tagValue == "" {
+		f.recordAction(tagValue, fieldValue, SkipNoTag)
+		return nil
+	}
+
+	// Skip non-zero fields, validating caller-supplied values against enum: tags
+	if !isZeroValue(fieldValue) {
+		if err := f.checkExistingEnum(fieldValue, tagValue); err != nil {
+			return f.newFieldError(err)
+		}
+		f.recordAction(tagValue, fieldValue, SkipNonZero)
+		return nil
+	}
+
+	// Map tags are key:value pairs, so a near miss such as "rate:5" is left to the conversion below
+	if f.opts.strictTags && !isMapField(fieldType.Type) {
+		if err := checkDirective(tagValue); err != nil {
+			return f.newFieldError(err)
+		}
+	}
+
+	if err := f.setFieldValue(fieldValue, fieldType, tagValue); err != nil {
+		if f.opts.strictTags && looksLikeDirective(tagValue) {
+			return f.newFieldError(fmt.Errorf(ErrUnknownDirective, directiveName(tagValue)))
+		}
+		return f.newFieldError(err)
+	}
+
+	// Apply the testfill_overrides companion tag to the filled elements
+	if overrides := fieldType.Tag.Get(TagOverrides); overrides != "" && fieldValue.Kind() == reflect.Slice {
+		if err := f.applyOverrides(fieldValue, overrides); err != nil {
+			return f.newFieldError(err)
+		}
+	}
+	f.recordAction(tagValue, fieldValue, "")
+
+	return nil
+}
+
+// =====================================================
+// Defaulters
+// =====================================================
+
+// Defaulter is implemented by types that set their own defaults in Go code, for values too
+// complex for tags. Fill calls TestfillDefault on a zero value of the type and copies the
+// fields it sets into the fields still zero, before applying tags to whatever is left.
+type Defaulter interface {
+	TestfillDefault()
+}
+
+// applyDefaults merges the defaults of a Defaulter into the zero exported fields of structValue,
+// keeping the values set by the caller and the fields excluded with WithSkip or WithOnly.
+func (f *filler) applyDefaults(structValue reflect.Value) {
+	defaults := reflect.New(structValue.Type())
+	defaulter, ok := defaults.Interface().(Defaulter)
+	if !ok {
+		return
+	}
+	defaulter.TestfillDefault()
+
+	defaults = defaults.Elem()
+	for i := 0; i < structValue.NumField(); i++ {
+		field := structValue.Field(i)
+		if !field.CanSet() || !isZeroValue(field) {
+			continue
+		}
+
+		f.enterPath(structValue.Type().Field(i).Name)
+		if !f.isSkippedPath() && f.isSelectedPath() {
+			field.Set(defaults.Field(i))
+		}
+		f.leavePath()
+	}
+}
+
+// =====================================================
+// Tag macros
+// =====================================================
+
+// Macro registry, guarded by macroMu
+var (
+	macroMu       sync.RWMutex
+	macroRegistry = make(map[string]string)
+)
+
+func getMacro(name string) (string, bool) {
+	macroMu.RLock()
+	defer macroMu.RUnlock()
+	macro, exists := macroRegistry[name]
+	return macro, exists
+}
+
+// expandMacro replaces a "@name" tag with the text of the registered macro.
+func expandMacro(tag string) (string, error) {
+	name, found := strings.CutPrefix(tag, TagMacro)
+	if !found {
+		return tag, nil
+	}
+
+	macro, exists := getMacro(name)
+	if !exists {
+		return "", fmt.Errorf(ErrUnknownMacro, name)
+	}
+	return macro, nil
+}
+
+// =====================================================
+// Tag parsing
+// =====================================================
+
+// DirectiveKind identifies the directive of a tag value.
+type DirectiveKind string
+
+// Directive kinds returned by ParseTag
+const (
+	DirectiveLiteral   DirectiveKind = "literal"
+	DirectiveFill      DirectiveKind = "fill"
+	DirectiveFactory   DirectiveKind = "factory"
+	DirectiveUnmarshal DirectiveKind = "unmarshal"
+	DirectiveVariants  DirectiveKind = "variants"
+	DirectiveProvider  DirectiveKind = "provider"
+	DirectiveRepeat    DirectiveKind = "repeat"
+	DirectiveSeq       DirectiveKind = "seq"
+	DirectiveRand      DirectiveKind = "rand"
+	DirectiveTimeZone  DirectiveKind = "tz"
+	DirectiveWhen      DirectiveKind = "when"
+	DirectiveClamp     DirectiveKind = "clamp"
+	DirectiveRequired  DirectiveKind = "required"
+	DirectiveTemplate  DirectiveKind = "tmpl"
+	DirectiveRange     DirectiveKind = "range"
+	DirectiveOneOf     DirectiveKind = "oneof"
+	DirectiveCopy      DirectiveKind = "copy"
+	DirectiveEnum      DirectiveKind = "enum"
+	DirectiveUUID      DirectiveKind = "uuid"
+	DirectiveIncr      DirectiveKind = "incr"
+	DirectiveBlank     DirectiveKind = "blank"
+	DirectiveDates     DirectiveKind = "dates"
+	DirectiveHex       DirectiveKind = "hex"
+	DirectiveJSONFill  DirectiveKind = "unmarshal+fill"
+	DirectiveRef       DirectiveKind = "ref"
+	DirectiveFake      DirectiveKind = "fake"
+	DirectiveFlags     DirectiveKind = "flags"
+)
+
+// Directive is the structured form of a tag value.
+//
+//	"42"                     -> {Kind: literal, Value: "42"}
+//	"fill:3"                 -> {Kind: fill, Args: ["3"]}
+//	"factory:Name:a:b"       -> {Kind: factory, Name: "Name", Args: ["a", "b"]}
+//	"unmarshal:{...}"        -> {Kind: unmarshal, Value: "{...}"}
+//	"variants:a,b"           -> {Kind: variants, Args: ["a", "b"]}
+//	"provider:key"           -> {Kind: provider, Value: "key"}
+//	"repeat:3:x"             -> {Kind: repeat, Args: ["3"], Value: "x"}
+//	"seq:100"                -> {Kind: seq, Value: "100"}
+//	"rand:unique"            -> {Kind: rand, Value: "unique"}
+//	"tz:Europe/Paris:value"  -> {Kind: tz, Name: "Europe/Paris", Value: "value"}
+//	"when:Type==premium:9"   -> {Kind: when, Name: "Type", Args: ["==", "premium"], Value: "9"}
+//	"clamp:0:100:250"        -> {Kind: clamp, Args: ["0", "100"], Value: "250"}
+//	"required"               -> {Kind: required}
+//	"tmpl:{{.Env}}-service"  -> {Kind: tmpl, Value: "{{.Env}}-service"}
+//	"range:0..10..2"         -> {Kind: range, Args: ["0", "10", "2"]}
+//	"oneof:red,green"        -> {Kind: oneof, Args: ["red", "green"]}
+//	"copy:Email"             -> {Kind: copy, Name: "Email"}
+//	"enum:on:on,off"         -> {Kind: enum, Args: ["on", "off"], Value: "on"}
+//	"uuid"                   -> {Kind: uuid}
+//	"incr:orders"            -> {Kind: incr, Value: "orders"}
+//	"blank"                  -> {Kind: blank}
+//	"dates:2023-01-01:24h:5" -> {Kind: dates, Args: ["24h", "5"], Value: "2023-01-01"}
+//	"hex:00ff"               -> {Kind: hex, Value: "00ff"}
+//	"unmarshal+fill:{...}"   -> {Kind: unmarshal+fill, Value: "{...}"}
+//	"ref:defaultTenant"      -> {Kind: ref, Name: "defaultTenant"}
+//	"fake:email"             -> {Kind: fake, Value: "email"}
+//	"flags:Read|Write"       -> {Kind: flags, Args: ["Read", "Write"]}
+type Directive struct {
+	Kind  DirectiveKind
+	Name  string
+	Args  []string
+	Value string
+	// Raw is the tag value as written, used when a directive does not apply to a field type.
+	Raw string
+}
+
+func parseDirective(tag string) (Directive, error) {
+	d := Directive{Kind: DirectiveLiteral, Value: tag, Raw: tag}
+
+	switch {
+	case tag == TagFill:
+		d.Kind, d.Value = DirectiveFill, ""
+	case tag == TagRequired:
+		d.Kind, d.Value = DirectiveRequired, ""
+	case tag == TagUUID:
+		d.Kind, d.Value = DirectiveUUID, ""
+	case tag == TagBlank:
+		d.Kind, d.Value = DirectiveBlank, ""
+	case strings.HasPrefix(tag, TagFill+":"):
+		d.Kind, d.Value = DirectiveFill, ""
+		d.Args = []string{strings.TrimPrefix(tag, TagFill+":")}
+	case strings.HasPrefix(tag, TagFactory):
+		d.Kind, d.Value = DirectiveFactory, ""
+		d.Name, d.Args = parseFactoryTag(strings.TrimPrefix(tag, TagFactory))
+	case strings.HasPrefix(tag, TagUnmarshal):
+		d.Kind, d.Value = DirectiveUnmarshal, strings.TrimPrefix(tag, TagUnmarshal)
+	case strings.HasPrefix(tag, TagVariant):
+		d.Kind, d.Value = DirectiveVariants, ""
+		d.Args = strings.Split(strings.TrimPrefix(tag, TagVariant), ",")
+		for i, variant := range d.Args {
+			d.Args[i] = strings.TrimSpace(variant)
+		}
+	case strings.HasPrefix(tag, TagProvider):
+		d.Kind, d.Value = DirectiveProvider, strings.TrimPrefix(tag, TagProvider)
+	case strings.HasPrefix(tag, TagRef):
+		d.Kind, d.Value = DirectiveRef, ""
+		d.Name = strings.TrimSpace(strings.TrimPrefix(tag, TagRef))
+	case strings.HasPrefix(tag, TagRepeat):
+		parts := strings.SplitN(strings.TrimPrefix(tag, TagRepeat), ":", 2)
+		if len(parts) != 2 {
+			return Directive{}, fmt.Errorf(ErrInvalidRepeat, tag)
+		}
+		d.Kind, d.Args, d.Value = DirectiveRepeat, []string{strings.TrimSpace(parts[0])}, strings.TrimSpace(parts[1])
+	case tag == TagIncr:
+		d.Kind, d.Value = DirectiveIncr, ""
+	case strings.HasPrefix(tag, TagIncr+":"):
+		d.Kind, d.Value = DirectiveIncr, strings.TrimPrefix(tag, TagIncr+":")
+	case tag == TagSeq:
+		d.Kind, d.Value = DirectiveSeq, ""
+	case strings.HasPrefix(tag, TagSeq+":"):
+		d.Kind, d.Value = DirectiveSeq, strings.TrimPrefix(tag, TagSeq+":")
+	case strings.HasPrefix(tag, TagRand):
+		d.Kind, d.Value = DirectiveRand, strings.TrimPrefix(tag, TagRand)
+	case strings.HasPrefix(tag, TagFlags):
+		d.Kind, d.Value = DirectiveFlags, ""
+		d.Args = strings.Split(strings.TrimPrefix(tag, TagFlags), "|")
+		for i, flag := range d.Args {
+			d.Args[i] = strings.TrimSpace(flag)
+		}
+	case strings.HasPrefix(tag, TagFake):
+		d.Kind, d.Value = DirectiveFake, strings.TrimSpace(strings.TrimPrefix(tag, TagFake))
+	case strings.HasPrefix(tag, TagTimeZone):
+		d.Kind = DirectiveTimeZone
+		d.Name, d.Value, _ = strings.Cut(strings.TrimPrefix(tag, TagTimeZone), ":")
+	case strings.HasPrefix(tag, TagWhen):
+		condition, value, found := strings.Cut(strings.TrimPrefix(tag, TagWhen), ":")
+		if !found {
+			return Directive{}, fmt.Errorf(ErrInvalidCondition, tag)
+		}
+		operator := "=="
+		if strings.Contains(condition, "!=") {
+			operator = "!="
+		}
+		name, literal, found := strings.Cut(condition, operator)
+		if !found || strings.TrimSpace(name) == "" {
+			return Directive{}, fmt.Errorf(ErrInvalidCondition, tag)
+		}
+		d.Kind, d.Name, d.Value = DirectiveWhen, strings.TrimSpace(name), value
+		d.Args = []string{operator, strings.TrimSpace(literal)}
+	case strings.HasPrefix(tag, TagRange):
+		d.Kind, d.Value = DirectiveRange, ""
+		d.Args = strings.Split(strings.TrimPrefix(tag, TagRange), "..")
+		if len(d.Args) != 2 && len(d.Args) != 3 {
+			return Directive{}, fmt.Errorf(ErrInvalidRange, tag)
+		}
+		for i, arg := range d.Args {
+			d.Args[i] = strings.TrimSpace(arg)
+		}
+	case strings.HasPrefix(tag, TagJSONFill):
+		d.Kind, d.Value = DirectiveJSONFill, strings.TrimPrefix(tag, TagJSONFill)
+	case strings.HasPrefix(tag, TagHex):
+		d.Kind, d.Value = DirectiveHex, strings.TrimSpace(strings.TrimPrefix(tag, TagHex))
+	case strings.HasPrefix(tag, TagDates):
+		// The start may be an RFC3339 time containing colons, so step and count are cut from the end
+		rest := strings.TrimPrefix(tag, TagDates)
+		parts := make([]string, 2)
+		for i := 1; i >= 0; i-- {
+			index := strings.LastIndex(rest, ":")
+			if index < 0 {
+				return Directive{}, fmt.Errorf(ErrInvalidDates, tag)
+			}
+			rest, parts[i] = rest[:index], strings.TrimSpace(rest[index+1:])
+		}
+		d.Kind, d.Value, d.Args = DirectiveDates, strings.TrimSpace(rest), parts
+	case strings.HasPrefix(tag, TagOneOf):
+		d.Kind, d.Value = DirectiveOneOf, ""
+		d.Args = strings.Split(strings.TrimPrefix(tag, TagOneOf), ",")
+		for i, option := range d.Args {
+			d.Args[i] = strings.TrimSpace(option)
+		}
+	case strings.HasPrefix(tag, TagEnum):
+		value, allowed, found := strings.Cut(strings.TrimPrefix(tag, TagEnum), ":")
+		if !found || strings.TrimSpace(allowed) == "" {
+			return Directive{}, fmt.Errorf(ErrInvalidEnum, tag)
+		}
+		d.Kind, d.Value = DirectiveEnum, strings.TrimSpace(value)
+		d.Args = strings.Split(allowed, ",")
+		for i, option := range d.Args {
+			d.Args[i] = strings.TrimSpace(option)
+		}
+	case strings.HasPrefix(tag, TagCopy):
+		d.Kind, d.Value = DirectiveCopy, ""
+		d.Name = strings.TrimSpace(strings.TrimPrefix(tag, TagCopy))
+	case strings.HasPrefix(tag, TagTemplate):
+		d.Kind, d.Value = DirectiveTemplate, strings.TrimPrefix(tag, TagTemplate)
+	case strings.HasPrefix(tag, TagClamp):
+		parts := strings.Split(strings.TrimPrefix(tag, TagClamp), ":")
+		if len(parts) != 3 {
+			return Directive{}, fmt.Errorf(ErrInvalidClamp, tag)
+		}
+		d.Kind, d.Value = DirectiveClamp, strings.TrimSpace(parts[2])
+		d.Args = []string{strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])}
+	}
+	return d, nil
+}
+
+// stripComment removes the comment from a literal tag value such as "42 #the answer": everything
+// from the first unescaped "#" preceded by whitespace on, so values such as "#ff0000" or
+// "https://x/docs#install" are kept. "\#" stands for a literal "#". Directives are left untouched.
+func stripComment(tag string) string {
+	if directive, err := parseDirective(tag); err == nil && directive.Kind != DirectiveLiteral {
+		return tag
+	}
+
+	var value strings.Builder
+	for i := 0; i < len(tag); i++ {
+		switch {
+		case strings.HasPrefix(tag[i:], `\`+TagComment):
+			value.WriteString(TagComment)
+			i++
+		case strings.HasPrefix(tag[i:], TagComment) && i > 0 && (tag[i-1] == ' ' || tag[i-1] == '\t'):
+			return strings.TrimSpace(value.String())
+		default:
+			value.WriteByte(tag[i])
+		}
+	}
+	return value.String()
+}
+
+// =====================================================
+// Strict tags
+// =====================================================
+
+// directiveNames lists the directives recognized before the first colon of a tag value.
+var directiveNames = []string{
+	strings.TrimSuffix(TagFactory, ":"),
+	strings.TrimSuffix(TagUnmarshal, ":"),
+	strings.TrimSuffix(TagVariant, ":"),
+	strings.TrimSuffix(TagRand, ":"),
+	strings.TrimSuffix(TagRepeat, ":"),
+	strings.TrimSuffix(TagProvider, ":"),
+	strings.TrimSuffix(TagTimeZone, ":"),
+	strings.TrimSuffix(TagWhen, ":"),
+	strings.TrimSuffix(TagClamp, ":"),
+	strings.TrimSuffix(TagTemplate, ":"),
+	strings.TrimSuffix(TagUnix, ":"),
+	strings.TrimSuffix(TagRange, ":"),
+	strings.TrimSuffix(TagOneOf, ":"),
+	strings.TrimSuffix(TagCopy, ":"),
+	strings.TrimSuffix(TagEnum, ":"),
+	strings.TrimSuffix(TagDates, ":"),
+	strings.TrimSuffix(TagHex, ":"),
+	strings.TrimSuffix(TagRef, ":"),
+	strings.TrimSuffix(TagFake, ":"),
+	strings.TrimSuffix(TagFlags, ":"),
+	TagFill,
+	TagSeq,
+	TagRequired,
+	TagUUID,
+	TagIncr,
+}
+
+// directivePattern matches tag values starting with a word followed by a colon.
+var directivePattern = regexp.MustCompile(`^[A-Za-z_]+:`)
+
+func looksLikeDirective(tag string) bool {
+	return directivePattern.MatchString(tag) && !isDirective(directiveName(tag))
+}
+
+func directiveName(tag string) string {
+	return tag[:strings.Index(tag, ":")]
+}
+
+func isDirective(name string) bool {
+	for _, known := range directiveNames {
+		if name == known {
+			return true
+		}
+	}
+	return false
+}
+
+// checkDirective rejects tags whose directive name is a near miss of a known directive.
+// Unknown directives that are not near misses are only rejected when the tag also fails
+// to convert as a literal value, since values like "key:value" are valid map tags.
+func checkDirective(tag string) error {
+	if !looksLikeDirective(tag) {
+		return nil
+	}
+
+	name := directiveName(tag)
+	for _, known := range directiveNames {
+		maxDistance := 1
+		if len(known) > 4 {
+			maxDistance = 2
+		}
+		if editDistance(strings.ToLower(name), known) <= maxDistance {
+			return fmt.Errorf(ErrMisspelledDirective, name, known)
+		}
+	}
+	return nil
+}
+
+// isMapField reports whether t is a map or a pointer to one.
+func isMapField(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Map
+}
+
+// editDistance returns the optimal string alignment distance between a and b, counting
+// insertions, deletions, substitutions and transpositions of adjacent characters.
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := 0; j <= len(b); j++ {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+// =====================================================
+// Field references
+// =====================================================
+
+// fieldReferencePattern matches ${Field} references to sibling fields in tag values.
+var fieldReferencePattern = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// hasFieldReferences reports whether a tag is a literal value referencing sibling fields. The
+// bodies of directives such as unmarshal: or tmpl: are left alone, so they may contain "${".
+func hasFieldReferences(tag string) bool {
+	if !fieldReferencePattern.MatchString(tag) {
+		return false
+	}
+	directive, err := parseDirective(tag)
+	return err != nil || directive.Kind == DirectiveLiteral
+}
+
+// fillReferencingFields fills the fields whose tags reference sibling fields, resolving
+// referenced fields first so references can be chained, and rejecting circular references.
+func (f *filler) fillReferencingFields(structValue reflect.Value, references map[string]string, order []string, variants []string) error {
+	structType := structValue.Type()
+	resolving := make(map[string]bool)
+
+	var resolve func(name string) error
+	resolve = func(name string) error {
+		tagValue, pending := references[name]
+		if !pending {
+			return nil
+		}
+		fieldType, _ := structType.FieldByName(name)
+		fieldValue := structValue.FieldByIndex(fieldType.Index)
+
+		if resolving[name] {
+			return f.fieldError(name, fmt.Errorf(ErrCircularReference, name))
+		}
+		resolving[name] = true
+
+		if strings.HasPrefix(tagValue, TagCopy) {
+			err := f.copyFieldValue(structValue, fieldType, tagValue, resolve)
+			delete(references, name)
+			return err
+		}
+
+		// fill:count=Field is fill:${Field}, restricted to integer fields
+		if ref, found := strings.CutPrefix(tagValue, TagCountOf); found {
+			ref = strings.TrimSpace(ref)
+			if refType, exists := structType.FieldByName(ref); exists && !isInteger(refType.Type.Kind()) {
+				return f.fieldError(name, fmt.Errorf(ErrCountType, ref, refType.Type))
+			}
+			tagValue = TagFill + ":${" + ref + "}"
+		}
+
+		// Only resolve references when the field is going to be filled
+		if isZeroValue(fieldValue) {
+			var err error
+			tagValue, err = expandFieldReferences(tagValue, func(ref string) (string, error) {
+				refType, exists := structType.FieldByName(ref)
+				if !exists || !refType.IsExported() {
+					return "", fmt.Errorf(ErrUnknownReference, ref)
+				}
+				if err := resolve(ref); err != nil {
+					return "", err
+				}
+				refValue, err := siblingValue(structValue, refType)
+				if err != nil {
+					return "", err
+				}
+				return formatValue(refValue), nil
+			})
+			if err != nil {
+				// Errors of referenced fields are already reported against them
+				var fieldErr *FieldError
+				if errors.As(err, &fieldErr) {
+					return err
+				}
+				return f.fieldError(name, err)
+			}
+		}
+
+		delete(references, name)
+		return f.fillField(fieldValue, fieldType, tagValue, variants)
+	}
+
+	for _, name := range order {
+		if err := resolve(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyFieldValue sets a field tagged copy:Source to the value of its sibling Source, once that
+// field is resolved. The value must be assignable or convertible to the field type.
+func (f *filler) copyFieldValue(structValue reflect.Value, fieldType reflect.StructField, tagValue string, resolve func(string) error) error {
+	source := strings.TrimSpace(strings.TrimPrefix(tagValue, TagCopy))
+	sourceType, exists := structValue.Type().FieldByName(source)
+	if !exists || !sourceType.IsExported() {
+		return f.fieldError(fieldType.Name, fmt.Errorf(ErrUnknownReference, source))
+	}
+	if err := resolve(source); err != nil {
+		return err
+	}
+
+	f.enterPath(fieldType.Name)
+	defer f.leavePath()
+
+	fieldValue := structValue.FieldByIndex(fieldType.Index)
+	if f.isSkippedPath() {
+		f.recordAction(tagValue, fieldValue, SkipPath)
+		return nil
+	}
+	if !f.isSelectedPath() {
+		f.recordAction(tagValue, fieldValue, SkipNotOnly)
+		return nil
+	}
+	if !isZeroValue(fieldValue) {
+		f.recordAction(tagValue, fieldValue, SkipNonZero)
+		return nil
+	}
+
+	sourceValue, err := siblingValue(structValue, sourceType)
+	if err != nil {
+		return f.newFieldError(err)
+	}
+
+	// Converting numbers to strings would yield runes, so only strings convert to strings
+	switch {
+	case sourceType.Type.AssignableTo(fieldType.Type):
+		fieldValue.Set(sourceValue)
+	case canConvert(sourceType.Type, fieldType.Type):
+		fieldValue.Set(sourceValue.Convert(fieldType.Type))
+	default:
+		return f.newFieldError(fmt.Errorf(ErrCopyType, source, sourceType.Type, fieldType.Type))
+	}
+
+	f.recordAction(tagValue, fieldValue, "")
+	return nil
+}
+
+// fillConditionalFields fills the fields tagged "when:Field==value:tag" (or !=) whose condition
+// holds, comparing the string form of the sibling field once the other fields are filled.
+// Fields whose condition does not hold are left untouched.
+func (f *filler) fillConditionalFields(structValue reflect.Value, conditionals []reflect.StructField, variants []string) error {
+	structType := structValue.Type()
+
+	for _, fieldType := range conditionals {
+		fieldValue := structValue.FieldByIndex(fieldType.Index)
+		tagValue, err := expandMacro(getTagValueForVariant(fieldType, variants))
+		if err != nil {
+			return f.fieldError(fieldType.Name, err)
+		}
+		directive, err := parseDirective(tagValue)
+		if err != nil {
+			return f.fieldError(fieldType.Name, err)
+		}
+
+		refType, exists := structType.FieldByName(directive.Name)
+		if !exists || !refType.IsExported() {
+			return f.fieldError(fieldType.Name, fmt.Errorf(ErrUnknownReference, directive.Name))
+		}
+
+		refValue, err := siblingValue(structValue, refType)
+		if err != nil {
+			return f.fieldError(fieldType.Name, err)
+		}
+
+		operator, literal := directive.Args[0], directive.Args[1]
+		equal := formatValue(refValue) == literal
+		if equal != (operator == "==") {
+			f.enterPath(fieldType.Name)
+			f.recordAction(directive.Raw, fieldValue, SkipWhen)
+			f.leavePath()
+			continue
+		}
+
+		value := stripComment(directive.Value)
+		if hasFieldReferences(value) {
+			references := map[string]string{fieldType.Name: value}
+			err = f.fillReferencingFields(structValue, references, []string{fieldType.Name}, variants)
+		} else {
+			err = f.fillField(fieldValue, fieldType, value, variants)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyOverrides sets fields of individual slice elements from a testfill_overrides tag such
+// as "1.Name=Zed;0.Address.City=Paris", after the elements have been filled. Values accept
+// the same syntax as testfill tags and replace whatever the element was filled with.
+func (f *filler) applyOverrides(slice reflect.Value, overrides string) error {
+	for _, override := range strings.Split(overrides, ";") {
+		override = strings.TrimSpace(override)
+		if override == "" {
+			continue
+		}
+
+		target, value, found := strings.Cut(override, "=")
+		indexStr, path, hasPath := strings.Cut(strings.TrimSpace(target), ".")
+		index, err := strconv.Atoi(indexStr)
+		if !found || !hasPath || err != nil {
+			return fmt.Errorf(ErrInvalidOverride, override)
+		}
+		if index < 0 || index >= slice.Len() {
+			return fmt.Errorf(ErrOverrideIndex, index, slice.Len())
+		}
+
+		field, err := fieldByPath(slice.Index(index), path)
+		if err != nil {
+			return fmt.Errorf(ErrOverrideField, override, err)
+		}
+
+		overridden := reflect.New(field.Type()).Elem()
+		if err := f.setFieldValue(overridden, reflect.StructField{}, strings.TrimSpace(value)); err != nil {
+			return err
+		}
+		field.Set(overridden)
+	}
+	return nil
+}
+
+// fieldByPath returns the exported field at a dotted path such as "Address.City", allocating
+// nil struct pointers along the way.
+func fieldByPath(v reflect.Value, path string) (reflect.Value, error) {
+	for _, name := range strings.Split(path, ".") {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, fmt.Errorf(ErrUnknownField, name)
+		}
+		fieldType, exists := v.Type().FieldByName(name)
+		if !exists || !fieldType.IsExported() {
+			return reflect.Value{}, fmt.Errorf(ErrUnknownField, name)
+		}
+		v = v.FieldByIndex(fieldType.Index)
+	}
+	return v, nil
+}
+
+// siblingValue reads a field referenced by a tag, which may be promoted from an embedded struct.
+// A nil embedded pointer on the way is reported as an error instead of panicking.
+func siblingValue(structValue reflect.Value, field reflect.StructField) (reflect.Value, error) {
+	value, err := structValue.FieldByIndexErr(field.Index)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf(ErrNilEmbedded, field.Name)
+	}
+	return value, nil
+}
+
+// expandFieldReferences replaces every ${Field} reference in tag with the value returned by lookup.
+func expandFieldReferences(tag string, lookup func(string) (string, error)) (string, error) {
+	var expanded strings.Builder
+	last := 0
+	for _, match := range fieldReferencePattern.FindAllStringSubmatchIndex(tag, -1) {
+		value, err := lookup(tag[match[2]:match[3]])
+		if err != nil {
+			return "", err
+		}
+		expanded.WriteString(tag[last:match[0]])
+		expanded.WriteString(value)
+		last = match[1]
+	}
+	expanded.WriteString(tag[last:])
+	return expanded.String(), nil
+}
+
+// fillElement fills a struct held by a collection, tracking its index or key in the field path.
+func (f *filler) fillElement(segment string, elemValue reflect.Value, variants []string) error {
+	f.enterPath(segment)
+	defer f.leavePath()
+	return f.fillStructWithVariant(elemValue, variants)
+}
+
+// fillIndexedElement fills the struct at index i of a slice or array, exposing the index to seq tags.
+func (f *filler) fillIndexedElement(i int, elemValue reflect.Value, variants []string) error {
+	previousIndex := f.elementIndex
+	f.elementIndex = i
+	defer func() { f.elementIndex = previousIndex }()
+	return f.fillElement(indexSegment(i), elemValue, variants)
+}
+
+func indexSegment(i int) string {
+	return fmt.Sprintf("[%d]", i)
+}
+
+func keySegment(key reflect.Value) string {
+	return fmt.Sprintf("[%v]", key.Interface())
+}
+
+// fieldError wraps err as a FieldError for the named field of the struct being filled.
+func (f *filler) fieldError(name string, err error) error {
+	f.enterPath(name)
+	defer f.leavePath()
+	return f.newFieldError(err)
+}
+
+// enterPath and leavePath track the path of the field being filled, e.g. "Users[2].Address.City".
+func (f *filler) enterPath(segment string) {
+	f.path = append(f.path, segment)
+}
+
+func (f *filler) leavePath() {
+	f.path = f.path[:len(f.path)-1]
+}
+
+func (f *filler) currentPath() string {
+	return joinPath(f.path)
+}
+
+// joinPath renders path segments as a dotted path, attaching index and key segments directly.
+func joinPath(segments []string) string {
+	var path strings.Builder
+	for i, segment := range segments {
+		if i > 0 && !strings.HasPrefix(segment, "[") {
+			path.WriteString(".")
+		}
+		path.WriteString(segment)
+	}
+	return path.String()
+}
+
+// isSkippedPath reports whether the current field matches a WithSkip path.
+func (f *filler) isSkippedPath() bool {
+	for _, skip := range f.opts.skipPaths {
+		for _, path := range f.matchablePaths() {
+			if skip == path {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isSelectedPath reports whether the current field is to be filled under WithOnly: it matches a
+// listed path, is nested inside one, or leads to one. Without WithOnly every field is selected.
+func (f *filler) isSelectedPath() bool {
+	if len(f.opts.onlyPaths) == 0 {
+		return true
+	}
+
+	for _, only := range f.opts.onlyPaths {
+		for _, path := range f.matchablePaths() {
+			if only == path || isPathPrefix(only, path) || isPathPrefix(path, only) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchablePaths returns the current path as written ("Users[0].Name") and with slice indexes
+// and map keys left out ("Users.Name"), the two forms WithSkip and WithOnly paths match.
+func (f *filler) matchablePaths() [2]string {
+	var fields []string
+	for _, segment := range f.path {
+		if !strings.HasPrefix(segment, "[") {
+			fields = append(fields, segment)
+		}
+	}
+	return [2]string{f.currentPath(), strings.Join(fields, ".")}
+}
+
+// isPathPrefix reports whether path lies inside the field at prefix, e.g. "Address.City" in "Address".
+func isPathPrefix(prefix, path string) bool {
+	return strings.HasPrefix(path, prefix+".") || strings.HasPrefix(path, prefix+"[")
+}
+
+// newFieldError wraps err as a FieldError for the field currently being filled. When err already
+// carries a FieldError from a deeper level, it is wrapped as a plain error instead so that
+// errors.As keeps returning the innermost failing field.
+func (f *filler) newFieldError(err error) error {
+	var fieldErr *FieldError
+	if errors.As(err, &fieldErr) {
+		return fmt.Errorf(ErrSetField, f.currentPath(), err)
+	}
+
+	last := len(f.path) - 1
+	return &FieldError{
+		Path:  append([]string(nil), f.path[:last]...),
+		Field: f.path[last],
+		Cause: err,
+	}
+}
+
+// =====================================================
+// Reflection utility functions
+// =====================================================
+
+// Zero checker registry, guarded by zeroCheckerMu
+var (
+	zeroCheckerMu       sync.RWMutex
+	zeroCheckerRegistry = make(map[reflect.Type]func(reflect.Value) bool)
+)
+
+func getZeroChecker(t reflect.Type) (func(reflect.Value) bool, bool) {
+	zeroCheckerMu.RLock()
+	defer zeroCheckerMu.RUnlock()
+	checker, exists := zeroCheckerRegistry[t]
+	return checker, exists
+}
+
+func isZeroValue(v reflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+	if checker, exists := getZeroChecker(v.Type()); exists {
+		return checker(v)
+	}
+	return v.IsZero()
+}
+
+// isSyncType reports whether t is one of the sync or sync/atomic types, such as sync.Mutex,
+// whose state must not be modified by filling.
+func isSyncType(t reflect.Type) bool {
+	return t.PkgPath() == "sync" || t.PkgPath() == "sync/atomic"
+}
+
+// hasTaggedFields reports whether any exported field of the struct type has a tag value for the variant.
+func hasTaggedFields(structType reflect.Type, variants []string) bool {
+	for i := 0; i < structType.NumField(); i++ {
+		fieldType := structType.Field(i)
+		if fieldType.IsExported() && getTagValueForVariant(fieldType, variants) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// getTagValueForVariant gets the appropriate tag value based on the variants
+// Variants are checked in priority order, looking for a "testfill_<variant>" tag for each
+// An empty variant or DefaultVariant selects the default "testfill" tag, as does running out of variants
+func getTagValueForVariant(fieldType reflect.StructField, variants []string) string {
+	for _, variant := range variants {
+		if variant == "" || variant == DefaultVariant {
+			return fieldType.Tag.Get(TagName)
+		}
+
+		// Look for variant-specific tag first
+		variantTag := TagName + "_" + variant
+		if value := fieldType.Tag.Get(variantTag); value != "" {
+			return value
+		}
+	}
+
+	// Fall back to default tag
+	return fieldType.Tag.Get(TagName)
+}
+
+// =====================================================
+// Nested struct handling
+// =====================================================
+
+// isNestedFillTag reports whether tag fills a nested struct through handleNestedFillWithVariant.
+func isNestedFillTag(tag string) bool {
+	return tag == TagFill || tag == TagShallow ||
+		strings.HasPrefix(tag, TagTuple) || strings.HasPrefix(tag, TagPinned) || strings.HasPrefix(tag, TagJSONFill)
+}
+
+// handleNestedFillWithVariant fills a struct, struct pointer, interface or struct array field tagged
+// with fill. With fill:shallow only the immediate struct is filled and its own fill fields are skipped.
+// With fill:tuple:1,2 the exported fields are set in order from the listed values. With
+// fill:variant=work the subtree is filled with the work variant instead of the parent's ones
+// (fill:variant=default pins the default tags, and several variants are comma-separated).
+// With unmarshal+fill:{...} the JSON is unmarshaled first and the fields it leaves zero are filled.
+func (f *filler) handleNestedFillWithVariant(field reflect.Value, fieldType reflect.StructField, tagValue string, variants []string) error {
+	if jsonData, found := strings.CutPrefix(tagValue, TagJSONFill); found && isZeroValue(field) {
+		if err := f.unmarshalJSON(field, jsonData); err != nil {
+			return f.newFieldError(err)
+		}
+		// unmarshal+fill:null leaves a pointer nil
+		if field.Kind() == reflect.Ptr && field.IsNil() {
+			return nil
+		}
+	}
+	if pinned, found := strings.CutPrefix(tagValue, TagPinned); found {
+		variants = strings.Split(pinned, ",")
+		for i, variant := range variants {
+			variants[i] = strings.TrimSpace(variant)
+		}
+	}
+	if tagValue == TagShallow {
+		f.shallow = true
+		defer func() { f.shallow = false }()
+	}
+	if values, found := strings.CutPrefix(tagValue, TagTuple); found {
+		return f.fillTuple(field, values)
+	}
+
+	// Remember what the caller set, which the testfill_json overrides must not replace
+	jsonData := fieldType.Tag.Get(TagJSON)
+	var before reflect.Value
+	if jsonData != "" {
+		before = reflect.New(field.Type()).Elem()
+		before.Set(deepCopy(field, make(map[uintptr]reflect.Value)))
+	}
+
+	switch field.Kind() {
+	case reflect.Struct:
+		if err := f.fillStructWithVariant(field, variants); err != nil {
+			return fmt.Errorf(ErrNestedStruct, fieldType.Name, err)
+		}
+	case reflect.Ptr:
+		if field.Type().Elem().Kind() != reflect.Struct {
+			return nil
+		}
+		if field.IsNil() {
+			// Leave the pointer nil when there is nothing to fill in the pointed struct
+			if f.opts.skipEmptyPointers && !hasTaggedFields(field.Type().Elem(), variants) {
+				return nil
+			}
+			// Create new instance if nil
+			newValue := reflect.New(field.Type().Elem())
+			field.Set(newValue)
+		}
+		if err := f.fillStructWithVariant(field.Elem(), variants); err != nil {
+			return fmt.Errorf(ErrNestedStructPtr, fieldType.Name, err)
+		}
+	case reflect.Interface:
+		if err := f.fillInterface(field, variants); err != nil {
+			return fmt.Errorf(ErrNestedStruct, fieldType.Name, err)
+		}
+	case reflect.Slice, reflect.Map:
+		if hasNestedStructs(field.Type()) {
+			return f.newFieldError(fmt.Errorf(ErrNestedStructFill, field.Type(), tagValue))
+		}
+		return nil
+	case reflect.Array:
+		if field.Type().Elem().Kind() != reflect.Struct {
+			return nil
+		}
+		for i := 0; i < field.Len(); i++ {
+			if err := f.fillIndexedElement(i, field.Index(i), variants); err != nil {
+				return fmt.Errorf(ErrNestedArray, fieldType.Name, i, err)
+			}
+		}
+	default:
+		return nil
+	}
+
+	// Merge the testfill_json overrides on top of the filled defaults
+	if jsonData != "" {
+		if err := f.mergeJSONOverrides(field, before, jsonData); err != nil {
+			return f.newFieldError(err)
+		}
+	}
+	return nil
+}
+
+// mergeJSONOverrides merges testfill_json overrides into a filled nested field. Like the fill
+// itself, it leaves alone the struct fields the caller set, as recorded in before.
+func (f *filler) mergeJSONOverrides(field, before reflect.Value, jsonData string) error {
+	if isZeroValue(before) {
+		return f.unmarshalJSON(field, jsonData)
+	}
+
+	target, previous := field, before
+	if field.Kind() == reflect.Ptr {
+		target, previous = field.Elem(), before.Elem()
+	}
+	if target.Kind() != reflect.Struct {
+		return nil
+	}
+
+	merged := reflect.New(target.Type())
+	merged.Elem().Set(deepCopy(target, make(map[uintptr]reflect.Value)))
+	if err := f.unmarshalJSONValue(merged.Interface(), jsonData); err != nil {
+		return err
+	}
+	for i := 0; i < target.NumField(); i++ {
+		if target.Field(i).CanSet() && isZeroValue(previous.Field(i)) {
+			target.Field(i).Set(merged.Elem().Field(i))
+		}
+	}
+	return nil
+}
+
+// fillTuple fills a struct or struct pointer field from the comma separated values of a
+// fill:tuple: tag, assigned to the exported fields in declaration order. Fields that are
+// already set keep their value.
+func (f *filler) fillTuple(field reflect.Value, values string) error {
+	if field.Kind() == reflect.Ptr && field.Type().Elem().Kind() == reflect.Struct {
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		field = field.Elem()
+	}
+	if field.Kind() != reflect.Struct {
+		return f.newFieldError(fmt.Errorf(ErrTupleType, field.Type()))
+	}
+
+	var exported []int
+	for i := 0; i < field.NumField(); i++ {
+		if field.Type().Field(i).IsExported() {
+			exported = append(exported, i)
+		}
+	}
+
+	parts := strings.Split(values, ",")
+	if len(parts) != len(exported) {
+		return f.newFieldError(fmt.Errorf(ErrTupleCount, field.Type(), len(exported), len(parts)))
+	}
+
+	for i, index := range exported {
+		elem, name := field.Field(index), field.Type().Field(index).Name
+		if !isZeroValue(elem) {
+			continue
+		}
+		if err := f.setFieldValue(elem, reflect.StructField{}, strings.TrimSpace(parts[i])); err != nil {
+			return f.fieldError(name, err)
+		}
+	}
+	return nil
+}
+
+// =====================================================
+// Field value setting
+// =====================================================
+
+func (f *filler) setFieldValue(field reflect.Value, _ reflect.StructField, tag string) error {
+	directive, err := parseDirective(tag)
+	if err == nil && (directive.Kind != DirectiveProvider || getValueProvider() != nil) {
+		return f.setDirectiveValue(field, directive)
+	}
+	if err == nil {
+		err = fmt.Errorf(ErrNoProvider, directive.Value)
+	}
+
+	// A tag that only looks like a directive, such as "repeat:daily" on a string, a map tagged
+	// "range:10,max:20" or a "provider:" tag without a registered provider, is a literal value
+	if literalErr := f.setLiteralValue(field, tag); literalErr != nil {
+		return err
+	}
+	return nil
+}
+
+// setLiteralValue converts a value produced at fill time, such as a provider result, the way a
+// literal tag is converted, so that values like "incr" or "fill" are stored as given.
+func (f *filler) setLiteralValue(field reflect.Value, value string) error {
+	return f.setDirectiveValue(field, Directive{Kind: DirectiveLiteral, Value: value, Raw: value})
+}
+
+func (f *filler) setDirectiveValue(field reflect.Value, directive Directive) error {
+	// These directives take precedence over the kind-specific syntax, so for instance a JSON
+	// array fills a struct slice directly instead of being read as fill: or variants:
+	switch directive.Kind {
+	case DirectiveUnmarshal:
+		return f.unmarshalJSON(field, directive.Value)
+	case DirectiveFactory:
+		return f.callFactoryFunction(field, directive.Name, directive.Args)
+	case DirectiveProvider:
+		return f.setProvidedValue(field, directive.Value)
+	case DirectiveRef:
+		return setRefValue(field, directive.Name)
+	case DirectiveTemplate:
+		return f.setTemplateValue(field, directive.Value)
+	case DirectiveOneOf:
+		return f.setOneOfValue(field, directive)
+	case DirectiveUUID:
+		return f.setFieldValue(field, reflect.StructField{}, f.newUUID())
+	}
+
+	// Registered converters handle the whole tag for their type
+	if _, exists := getConverter(field.Type()); exists {
+		return setConvertedValue(field, directive.Raw)
+	}
+
+	// json.RawMessage keeps a plain JSON tag verbatim instead of reading it as a byte slice
+	if field.Type() == rawMessageType {
+		return setRawMessageValue(field, directive.Raw)
+	}
+
+	// Non-struct types implementing encoding.TextUnmarshaler (e.g. net.IP) parse the tag themselves
+	if field.Kind() != reflect.Struct && isTextUnmarshaler(field.Type()) {
+		return setTextValue(field, directive.Raw)
+	}
+
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.String, reflect.Bool:
+		return f.setPrimitiveValue(field, directive)
+	case reflect.Slice:
+		return f.setSliceValue(field, directive)
+	case reflect.Array:
+		return f.setArrayValue(field, directive)
+	case reflect.Map:
+		return f.setMapValue(field, directive)
+	case reflect.Ptr:
+		return f.setPtrValue(field, directive)
+	case reflect.Struct:
+		return f.setStructValue(field, directive)
+	case reflect.Interface:
+		return f.setAnyValue(field, directive)
+	default:
+		return withKind(ErrUnsupported, fmt.Errorf(ErrUnsupportedField, field.Kind()))
+	}
+}
+
+func (f *filler) setSliceValue(field reflect.Value, directive Directive) error {
+	elemType := field.Type().Elem()
+
+	// Handle struct, struct pointer and interface slices with special "fill:count" syntax
+	if ((elemType.Kind() == reflect.Struct || elemType.Kind() == reflect.Interface) && !hasTextConversion(elemType)) || isStructPtr(elemType) {
+		return f.setStructSliceValue(field, directive, elemType)
+	}
+
+	// Support "repeat:count:value" syntax for slices of a repeated value
+	if directive.Kind == DirectiveRepeat {
+		return f.setRepeatedSliceValue(field, directive)
+	}
+
+	// Support "range:start..end[..step]" syntax for integer slices
+	if directive.Kind == DirectiveRange {
+		return f.setRangeSliceValue(field, directive)
+	}
+
+	// Support "dates:start:step:count" syntax for time slices
+	if directive.Kind == DirectiveDates {
+		return f.setDatesSliceValue(field, directive)
+	}
+
+	// Support "hex:00ff" syntax for byte slices and, through setArrayValue, byte arrays
+	if directive.Kind == DirectiveHex {
+		return setHexSliceValue(field, directive)
+	}
+
+	// Nested containers list their structs one by one instead of using fill:N
+	if directive.Kind == DirectiveFill && hasNestedStructs(field.Type()) {
+		return fmt.Errorf(ErrNestedStructFill, field.Type(), directive.Raw)
+	}
+
+	// Handle primitive slices, including slices of nested containers
+	return f.setContainerValue(field, directive.Raw, 0)
+}
+
+func (f *filler) setRepeatedSliceValue(field reflect.Value, directive Directive) error {
+	elemType := field.Type().Elem()
+	count, err := strconv.Atoi(directive.Args[0])
+	if err != nil || count < 0 {
+		return fmt.Errorf(ErrInvalidRepeat, directive.Raw)
+	}
+	if err := f.checkSliceCount(count); err != nil {
+		return err
+	}
+
+	value := directive.Value
+	slice := reflect.MakeSlice(field.Type(), count, count)
+	for i := 0; i < count; i++ {
+		// Convert per element so container values such as maps are not shared
+		elemValue, err := f.convertContainerElement(value, elemType, 0, indexSegment(i))
+		if err != nil {
+			if isContainer(elemType) {
+				return err
+			}
+			return withKind(ErrUnsupported, fmt.Errorf(ErrUnsupportedSliceType, elemType.Kind()))
+		}
+		slice.Index(i).Set(elemValue)
+	}
+
+	field.Set(slice)
+	return nil
+}
+
+// maxRangeCount bounds the number of values of a range: slice, so a mistyped bound fails
+// instead of exhausting memory.
+const maxRangeCount = 1 << 24
+
+// setRangeSliceValue fills an integer slice with the values from start to end, both included,
+// stepping by step (1, or -1 for descending ranges, when omitted).
+func (f *filler) setRangeSliceValue(field reflect.Value, directive Directive) error {
+	elemType := field.Type().Elem()
+	if !isInteger(elemType.Kind()) {
+		return fmt.Errorf(ErrRangeType, elemType)
+	}
+
+	bounds := make([]int64, len(directive.Args))
+	for i, arg := range directive.Args {
+		bound, err := parseInt(arg, 64)
+		if err != nil {
+			return fmt.Errorf(ErrInvalidRange, directive.Raw)
+		}
+		bounds[i] = bound
+	}
+
+	start, end, step := bounds[0], bounds[1], int64(1)
+	if end < start {
+		step = -1
+	}
+	if len(bounds) == 3 {
+		step = bounds[2]
+	}
+	if step == 0 || (end > start && step < 0) || (end < start && step > 0) {
+		return fmt.Errorf(ErrRangeStep, directive.Raw, step)
+	}
+
+	// Count the values in unsigned arithmetic, where the span and stride of any int64 range fit
+	span, stride := uint64(end)-uint64(start), uint64(step)
+	if step < 0 {
+		span, stride = uint64(start)-uint64(end), -uint64(step)
+	}
+	if span/stride >= maxRangeCount {
+		return fmt.Errorf(ErrRangeCount, directive.Raw, maxRangeCount)
+	}
+	count := int(span/stride) + 1
+	if err := f.checkSliceCount(count); err != nil {
+		return err
+	}
+
+	slice := reflect.MakeSlice(field.Type(), count, count)
+	for i, n := 0, start; i < count; i, n = i+1, n+step {
+		elemValue, err := convertStringToType(strconv.FormatInt(n, 10), elemType)
+		if err != nil {
+			return err
+		}
+		slice.Index(i).Set(elemValue)
+
+		// Stop before stepping past the end, which would overflow n
+		if i == count-1 {
+			break
+		}
+	}
+
+	field.Set(slice)
+	return nil
+}
+
+// setDatesSliceValue fills a time.Time slice with count times, the first at start (an RFC3339
+// time or a "2006-01-02" date) and each following one step later.
+func (f *filler) setDatesSliceValue(field reflect.Value, directive Directive) error {
+	elemType := field.Type().Elem()
+	if elemType != timeType {
+		return fmt.Errorf(ErrDatesType, elemType)
+	}
+
+	start, err := f.parseTime(directive.Value)
+	if err != nil {
+		if start, err = time.Parse(time.DateOnly, directive.Value); err != nil {
+			return fmt.Errorf(ErrDatesPart, "start", directive.Value)
+		}
+	}
+	step, err := time.ParseDuration(directive.Args[0])
+	if err != nil {
+		return fmt.Errorf(ErrDatesPart, "step", directive.Args[0])
+	}
+	count, err := strconv.Atoi(directive.Args[1])
+	if err != nil || count < 0 {
+		return fmt.Errorf(ErrDatesPart, "count", directive.Args[1])
+	}
+	if err := f.checkSliceCount(count); err != nil {
+		return err
+	}
+
+	slice := reflect.MakeSlice(field.Type(), count, count)
+	for i := 0; i < count; i++ {
+		slice.Index(i).Set(reflect.ValueOf(start.Add(time.Duration(i) * step)))
+	}
+	field.Set(slice)
+	return nil
+}
+
+// checkSliceCount rejects element counts above the WithMaxSliceCount limit.
+func (f *filler) checkSliceCount(count int) error {
+	if f.opts.maxSliceCount > 0 && count > f.opts.maxSliceCount {
+		return fmt.Errorf(ErrSliceCount, count, f.opts.maxSliceCount)
+	}
+	return nil
+}
+
+// setHexSliceValue fills a byte slice with the bytes a hex: tag decodes to.
+func setHexSliceValue(field reflect.Value, directive Directive) error {
+	elemType := field.Type().Elem()
+	if elemType.Kind() != reflect.Uint8 {
+		return fmt.Errorf(ErrHexType, elemType)
+	}
+
+	b, err := hex.DecodeString(directive.Value)
+	if err != nil {
+		return withKind(ErrConversion, fmt.Errorf(ErrInvalidHex, directive.Value, err))
+	}
+
+	// Set byte by byte, so named byte types are supported too
+	slice := reflect.MakeSlice(field.Type(), len(b), len(b))
+	for i, value := range b {
+		slice.Index(i).SetUint(uint64(value))
+	}
+	field.Set(slice)
+	return nil
+}
+
+func (f *filler) setStructSliceValue(field reflect.Value, directive Directive, elemType reflect.Type) error {
+	// Support "fill:count" syntax for struct slices
+	if directive.Kind == DirectiveFill && len(directive.Args) == 1 {
+		count, err := strconv.Atoi(directive.Args[0])
+		if err != nil || count < 0 {
+			return fmt.Errorf("invalid slice count format: %s", directive.Raw)
+		}
+		if err := f.checkSliceCount(count); err != nil {
+			return err
+		}
+
+		slice := reflect.MakeSlice(field.Type(), count, count)
+		for i := 0; i < count; i++ {
+			elemValue, err := f.newSliceElement(i, elemType, nil)
+			if err != nil {
+				return fmt.Errorf("failed to fill slice element %d: %w", i, err)
+			}
+			slice.Index(i).Set(elemValue)
+		}
+		field.Set(slice)
+		return nil
+	}
+
+	// Support "variants:name1,name2,name3" syntax for struct slices with different field values
+	if directive.Kind == DirectiveVariants {
+		variants := directive.Args
+		slice := reflect.MakeSlice(field.Type(), len(variants), len(variants))
+		for i, variant := range variants {
+			elemValue, err := f.newSliceElement(i, elemType, []string{variant})
+			if err != nil {
+				return fmt.Errorf("failed to fill slice element %d with variant %s: %w", i, variant, err)
+			}
+			slice.Index(i).Set(elemValue)
+		}
+		field.Set(slice)
+		return nil
+	}
+
+	return withKind(ErrUnsupported, fmt.Errorf(ErrUnsupportedSliceType, elemType.Kind()))
+}
+
+// newSliceElement creates and fills element i of a struct, struct pointer or interface slice.
+// Interface elements are created from the implementation registered for the interface.
+func (f *filler) newSliceElement(i int, elemType reflect.Type, variants []string) (reflect.Value, error) {
+	if elemType.Kind() != reflect.Interface {
+		elemValue, target := newStructElement(elemType)
+		return elemValue, f.fillIndexedElement(i, target, variants)
+	}
+
+	impl, target, err := newImplementation(elemType)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return impl, f.fillIndexedElement(i, target, variants)
+}
+
+// setArrayValue parses the tag as a slice of the array element type and copies it into the
+// array, so arrays accept the same syntax as slices as long as the value count matches.
+func (f *filler) setArrayValue(field reflect.Value, directive Directive) error {
+	slice := reflect.New(reflect.SliceOf(field.Type().Elem())).Elem()
+	if err := f.setSliceValue(slice, directive); err != nil {
+		return err
+	}
+
+	if slice.Len() != field.Len() {
+		return fmt.Errorf(ErrArrayLength, field.Len(), slice.Len())
+	}
+
+	reflect.Copy(field, slice)
+	return nil
+}
+
+func (f *filler) setMapValue(field reflect.Value, directive Directive) error {
+	keyType := field.Type().Key()
+	valueType := field.Type().Elem()
+
+	// Handle struct and struct pointer value maps with special "key:fill" syntax
+	if isNestedStruct(valueType) || isStructPtr(valueType) {
+		return f.setStructMapValue(field, directive, keyType, valueType)
+	}
+
+	// Handle primitive maps, including maps of nested containers
+	return f.setContainerValue(field, directive.Raw, 0)
+}
+
+// =====================================================
+// Nested container support
+// =====================================================
+
+// containerDelimiters lists the element and key/value separators of each container nesting
+// level, from the outermost to the innermost one. For instance a []map[string]int is filled
+// from "a=1;b=2,c=3" and a map[string][]int from "x:1;2,y:3".
+var containerDelimiters = []struct {
+	elem     string
+	keyValue string
+}{
+	{elem: ",", keyValue: ":"},
+	{elem: ";", keyValue: "="},
+}
+
+// setContainerValue fills a slice or map of primitives or nested containers from a tag
+// using the delimiters of the given nesting level.
+func (f *filler) setContainerValue(field reflect.Value, tag string, level int) error {
+	if level >= len(containerDelimiters) {
+		return fmt.Errorf(ErrContainerDepth, field.Type(), len(containerDelimiters))
+	}
+	delimiters := containerDelimiters[level]
+
+	if field.Kind() == reflect.Slice {
+		elemType := field.Type().Elem()
+		parts := strings.Split(tag, delimiters.elem)
+		slice := reflect.MakeSlice(field.Type(), len(parts), len(parts))
+
+		for i, part := range parts {
+			elemValue, err := f.convertContainerElement(strings.TrimSpace(part), elemType, level, indexSegment(i))
+			if err != nil {
+				if isContainer(elemType) || isNestedStruct(elemType) {
+					return err
+				}
+				return withKind(ErrUnsupported, fmt.Errorf(ErrUnsupportedSliceType, elemType.Kind()))
+			}
+			slice.Index(i).Set(elemValue)
+		}
+
+		field.Set(slice)
+		return nil
+	}
+
+	keyType := field.Type().Key()
+	valueType := field.Type().Elem()
+	m := reflect.MakeMap(field.Type())
+	pairs, err := parsePairs(tag, level)
+	if err != nil {
+		return err
+	}
+
+	for _, pair := range pairs {
+		keyValue, err := f.convertString(pair.Key, keyType)
+		if err != nil {
+			return withKind(ErrUnsupported, fmt.Errorf(ErrUnsupportedMapType, keyType.Kind(), valueType.Kind()))
+		}
+
+		valueValue, err := f.convertContainerElement(pair.Value, valueType, level, keySegment(keyValue))
+		if err != nil {
+			if isContainer(valueType) || isNestedStruct(valueType) {
+				return err
+			}
+			return withKind(ErrUnsupported, fmt.Errorf(ErrUnsupportedMapType, keyType.Kind(), valueType.Kind()))
+		}
+
+		m.SetMapIndex(keyValue, valueValue)
+	}
+
+	field.Set(m)
+	return nil
+}
+
+// parsePairs splits a map tag into its key/value pairs, in tag order, using the
+// delimiters of the given nesting level. Keys and values may be double-quoted to contain
+// delimiters, as in `"a,b":1,"c:d":2`.
+func parsePairs(tag string, level int) ([]Pair, error) {
+	delimiters := containerDelimiters[level]
+	items := splitUnquoted(tag, delimiters.elem)
+	pairs := make([]Pair, 0, len(items))
+
+	for _, item := range items {
+		kv := splitUnquoted(strings.TrimSpace(item), delimiters.keyValue)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf(ErrInvalidMapFormat, item)
+		}
+		pairs = append(pairs, Pair{Key: unquote(strings.TrimSpace(kv[0])), Value: unquote(strings.TrimSpace(kv[1]))})
+	}
+	return pairs, nil
+}
+
+// splitUnquoted splits s around each sep found outside double-quoted sections.
+func splitUnquoted(s, sep string) []string {
+	var parts []string
+	start, quoted := 0, false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quoted && s[i] == '\\':
+			i++
+		case s[i] == '"':
+			quoted = !quoted
+		case !quoted && strings.HasPrefix(s[i:], sep):
+			parts = append(parts, s[start:i])
+			start = i + len(sep)
+			i += len(sep) - 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// unquote returns s without its surrounding double quotes when it is a quoted Go string.
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		if unquoted, err := strconv.Unquote(s); err == nil {
+			return unquoted
+		}
+	}
+	return s
+}
+
+// convertContainerElement converts a slice element or map value, recursing into nested containers.
+// Arrays, as in map[string][3]int, are parsed like slices and must match their length. Nested
+// structs, as in []map[string]Bar, are filled from their tags for "fill" or with the variant the
+// value names; segment identifies the element in the field path.
+func (f *filler) convertContainerElement(s string, elemType reflect.Type, level int, segment string) (reflect.Value, error) {
+	if elemType.Kind() == reflect.Array {
+		f.enterPath(segment)
+		defer f.leavePath()
+		slice := reflect.New(reflect.SliceOf(elemType.Elem())).Elem()
+		if err := f.setContainerValue(slice, s, level+1); err != nil {
+			return reflect.Value{}, err
+		}
+		if slice.Len() != elemType.Len() {
+			return reflect.Value{}, f.newFieldError(fmt.Errorf(ErrArrayLength, elemType.Len(), slice.Len()))
+		}
+		elemValue := reflect.New(elemType).Elem()
+		reflect.Copy(elemValue, slice)
+		return elemValue, nil
+	}
+
+	if isContainer(elemType) {
+		f.enterPath(segment)
+		defer f.leavePath()
+		elemValue := reflect.New(elemType).Elem()
+		if err := f.setContainerValue(elemValue, s, level+1); err != nil {
+			return reflect.Value{}, err
+		}
+		return elemValue, nil
+	}
+
+	if isNestedStruct(elemType) {
+		var variants []string
+		if s != TagFill {
+			variants = []string{s}
+		}
+		elemValue := reflect.New(elemType).Elem()
+		return elemValue, f.fillElement(segment, elemValue, variants)
+	}
+	return f.convertString(s, elemType)
+}
+
+func isContainer(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice || t.Kind() == reflect.Map || t.Kind() == reflect.Array
+}
+
+// hasNestedStructs reports whether t is a container of containers of structs, such as []map[string]Bar.
+func hasNestedStructs(t reflect.Type) bool {
+	return isContainer(t) && isContainer(t.Elem()) && isNestedStruct(t.Elem().Elem())
+}
+
+// isNestedStruct reports whether t is a struct filled field by field rather than parsed from text.
+func isNestedStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && !hasTextConversion(t)
+}
+
+// isStructPtr reports whether t is a pointer to a struct filled field by field.
+func isStructPtr(t reflect.Type) bool {
+	return t.Kind() == reflect.Ptr && isNestedStruct(t.Elem())
+}
+
+// newStructElement allocates a struct container element, or the struct a *Struct element
+// points to, returning the element and the struct to fill.
+func newStructElement(elemType reflect.Type) (elem, target reflect.Value) {
+	if elemType.Kind() == reflect.Ptr {
+		elem = reflect.New(elemType.Elem())
+		return elem, elem.Elem()
+	}
+	elem = reflect.New(elemType).Elem()
+	return elem, elem
+}
+
+func (f *filler) setStructMapValue(field reflect.Value, directive Directive, keyType, valueType reflect.Type) error {
+	// Only support string keys for struct value maps
+	if keyType.Kind() != reflect.String {
+		return withKind(ErrUnsupported, fmt.Errorf(ErrUnsupportedMapType, keyType.Kind(), valueType.Kind()))
+	}
+
+	// Check if this is a variants syntax
+	if directive.Kind == DirectiveVariants {
+		return f.setStructMapWithVariants(field, directive.Args, valueType)
+	}
+
+	m := reflect.MakeMap(field.Type())
+	pairs, err := parsePairs(directive.Raw, 0)
+	if err != nil {
+		return err
+	}
+
+	for _, pair := range pairs {
+		keyStr, valueStr := pair.Key, pair.Value
+		keyValue := reflect.ValueOf(keyStr)
+
+		if valueStr == "fill" {
+			// Create and fill a new struct instance with default variant
+			value, structValue := newStructElement(valueType)
+			if err := f.fillElement(keySegment(keyValue), structValue, nil); err != nil {
+				return fmt.Errorf("failed to fill map value for key %s: %w", keyStr, err)
+			}
+			m.SetMapIndex(keyValue, value)
+		} else {
+			// Assume valueStr is a variant name
+			value, structValue := newStructElement(valueType)
+			if err := f.fillElement(keySegment(keyValue), structValue, []string{valueStr}); err != nil {
+				return fmt.Errorf("failed to fill map value for key %s with variant %s: %w", keyStr, valueStr, err)
+			}
+			m.SetMapIndex(keyValue, value)
+		}
+	}
+
+	field.Set(m)
+	return nil
+}
+
+func (f *filler) setStructMapWithVariants(field reflect.Value, items []string, valueType reflect.Type) error {
+	// Items come from "variants:key1=variant1,key2=variant2,..." syntax
+	m := reflect.MakeMap(field.Type())
+
+	for _, item := range items {
+		// All items must use key=variant syntax
+		kv := strings.Split(item, "=")
+		if len(kv) != 2 {
+			return fmt.Errorf("invalid key=variant format: %s (expected format: key=variant)", item)
+		}
+
+		keyStr := strings.TrimSpace(kv[0])
 		variant := strings.TrimSpace(kv[1])
 
 		keyValue := reflect.ValueOf(keyStr)
 
 		// Create and fill struct with the specified variant
-		structValue := reflect.New(valueType).Elem()
-		if err := fillStructWithVariant(structValue, variant); err != nil {
+		value, structValue := newStructElement(valueType)
+		if err := f.fillElement(keySegment(keyValue), structValue, []string{variant}); err != nil {
 			return fmt.Errorf("failed to fill map value for key %s with variant %s: %w", keyStr, variant, err)
 		}
-		m.SetMapIndex(keyValue, structValue)
+		m.SetMapIndex(keyValue, value)
+	}
+
+	field.Set(m)
+	return nil
+}
+
+// setPtrValue allocates the pointed value and fills it from the directive, so pointers to
+// primitives, slices and maps (*[]int, *map[string]int) take the same tags as their elements.
+// The nil and null keywords leave the pointer nil; use unmarshal:"nil" for a *string to "nil".
+func (f *filler) setPtrValue(field reflect.Value, directive Directive) error {
+	if directive.Kind == DirectiveLiteral && (directive.Raw == TagNil || directive.Raw == TagNull) {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+
+	elemType := field.Type().Elem()
+	elem := reflect.New(elemType).Elem()
+
+	err := f.setDirectiveValue(elem, directive)
+	if err != nil {
+		return err
+	}
+
+	field.Set(elem.Addr())
+	return nil
+}
+
+// setPrimitiveValue handles all primitive types (int, uint, uintptr, float, string, bool)
+func (f *filler) setPrimitiveValue(field reflect.Value, directive Directive) error {
+	if isInteger(field.Kind()) && directive.Kind == DirectiveSeq {
+		return f.setSeqValue(field, directive)
+	}
+
+	if directive.Kind == DirectiveRand {
+		return f.setRandomValue(field, directive.Value)
+	}
+
+	if directive.Kind == DirectiveFake {
+		return f.setFakeValue(field, directive.Value)
+	}
+
+	if directive.Kind == DirectiveClamp {
+		return setClampedValue(field, directive)
+	}
+
+	if directive.Kind == DirectiveEnum {
+		return f.setEnumValue(field, directive)
+	}
+
+	if directive.Kind == DirectiveIncr {
+		return f.setIncrValue(field, directive.Value)
+	}
+
+	if directive.Kind == DirectiveFlags {
+		return setFlagsValue(field, directive)
+	}
+
+	convertedValue, err := f.convertString(directive.Raw, field.Type())
+	if err != nil {
+		return err
+	}
+	field.Set(convertedValue)
+	return nil
+}
+
+// Flag names registered with RegisterFlags, by type, guarded by flagMu
+var (
+	flagMu       sync.RWMutex
+	flagRegistry = make(map[reflect.Type]map[string]uint64)
+)
+
+func getFlags(t reflect.Type) (map[string]uint64, bool) {
+	flagMu.RLock()
+	defer flagMu.RUnlock()
+	flags, exists := flagRegistry[t]
+	return flags, exists
+}
+
+// setFlagsValue ORs the registered values of the flags named by a "flags:A|B" tag.
+func setFlagsValue(field reflect.Value, directive Directive) error {
+	flags, exists := getFlags(field.Type())
+	if !exists || !isInteger(field.Kind()) {
+		return fmt.Errorf(ErrNoFlags, field.Type())
+	}
+
+	var bits uint64
+	for _, name := range directive.Args {
+		if name == "" {
+			continue
+		}
+		value, exists := flags[name]
+		if !exists {
+			return fmt.Errorf(ErrUnknownFlag, name, field.Type())
+		}
+		bits |= value
+	}
+
+	// Reject bits the field cannot hold instead of letting Convert drop them
+	var overflows bool
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		overflows = bits > math.MaxInt64 || field.OverflowInt(int64(bits))
+	default:
+		overflows = field.OverflowUint(bits)
+	}
+	if overflows {
+		return fmt.Errorf(ErrFlagOverflow, strings.Join(directive.Args, "|"), bits, field.Type())
+	}
+
+	field.Set(reflect.ValueOf(bits).Convert(field.Type()))
+	return nil
+}
+
+// Counters holds the incr sequences by namespace. It is safe for concurrent use.
+type Counters struct {
+	mu     sync.Mutex
+	values map[string]int64
+}
+
+// NewCounters creates a set of incr sequences, all starting at 1.
+func NewCounters() *Counters {
+	return &Counters{values: make(map[string]int64)}
+}
+
+// Reset restarts every sequence at 1.
+func (c *Counters) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values = make(map[string]int64)
+}
+
+func (c *Counters) next(namespace string) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[namespace]++
+	return c.values[namespace]
+}
+
+// packageCounters are the incr sequences of fill calls without WithCounters.
+var packageCounters = NewCounters()
+
+// ResetCounters restarts every incr sequence at 1, for deterministic assertions across tests.
+func ResetCounters() {
+	packageCounters.Reset()
+}
+
+// setIncrValue sets an integer to the next value of the counter for namespace ("incr" uses the
+// default one). Counters start at 1 and are shared by every fill call unless WithCounters is set.
+func (f *filler) setIncrValue(field reflect.Value, namespace string) error {
+	if !isInteger(field.Kind()) {
+		return fmt.Errorf(ErrIncrType, field.Type())
+	}
+
+	counters := packageCounters
+	if f.opts.counters != nil {
+		counters = f.opts.counters
+	}
+
+	convertedValue, err := convertStringToType(strconv.FormatInt(counters.next(namespace), 10), field.Type())
+	if err != nil {
+		return err
+	}
+	field.Set(convertedValue)
+	return nil
+}
+
+// setSeqValue sets an integer to its start value ("seq" starts at 0, "seq:100" at 100)
+// plus the index of the slice element being filled.
+func (f *filler) setSeqValue(field reflect.Value, directive Directive) error {
+	var start int64
+	if directive.Value != "" {
+		var err error
+		start, err = parseInt(directive.Value, 64)
+		if err != nil {
+			return fmt.Errorf(ErrInvalidSeq, directive.Raw, err)
+		}
+	}
+
+	convertedValue, err := convertStringToType(strconv.FormatInt(start+int64(f.elementIndex), 10), field.Type())
+	if err != nil {
+		return err
+	}
+	field.Set(convertedValue)
+	return nil
+}
+
+// setClampedValue handles "clamp:min:max:value" tags, parsing the value and limiting it to
+// the [min, max] range for signed, unsigned and floating-point fields.
+func setClampedValue(field reflect.Value, directive Directive) error {
+	if !isInteger(field.Kind()) && field.Kind() != reflect.Float32 && field.Kind() != reflect.Float64 {
+		return fmt.Errorf(ErrClampType, field.Type())
+	}
+
+	minValue, err := convertStringToType(directive.Args[0], field.Type())
+	if err != nil {
+		return fmt.Errorf(ErrClampBound, directive.Args[0], err)
+	}
+	maxValue, err := convertStringToType(directive.Args[1], field.Type())
+	if err != nil {
+		return fmt.Errorf(ErrClampBound, directive.Args[1], err)
+	}
+	if less(maxValue, minValue) {
+		return fmt.Errorf(ErrClampRange, directive.Args[0], directive.Args[1])
+	}
+
+	value, err := convertStringToType(directive.Value, field.Type())
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case less(value, minValue):
+		value = minValue
+	case less(maxValue, value):
+		value = maxValue
+	}
+	field.Set(value)
+	return nil
+}
+
+// less reports whether a is smaller than b, both being numeric values of the same kind.
+func less(a, b reflect.Value) bool {
+	switch {
+	case a.CanInt():
+		return a.Int() < b.Int()
+	case a.CanUint():
+		return a.Uint() < b.Uint()
+	default:
+		return a.Float() < b.Float()
+	}
+}
+
+// =====================================================
+// Enums
+// =====================================================
+
+// setEnumValue handles "enum:value:allowed1,allowed2" tags, filling the field with the value
+// after checking it is one of the allowed values.
+func (f *filler) setEnumValue(field reflect.Value, directive Directive) error {
+	value, err := f.convertString(directive.Value, field.Type())
+	if err != nil {
+		return err
+	}
+	if err := f.checkEnum(value, directive); err != nil {
+		return err
+	}
+	field.Set(value)
+	return nil
+}
+
+// checkExistingEnum validates a value set by the caller against the field's enum: tag, if any.
+func (f *filler) checkExistingEnum(field reflect.Value, tagValue string) error {
+	directive, err := parseDirective(tagValue)
+	if err != nil || directive.Kind != DirectiveEnum {
+		return err
+	}
+
+	for field.Kind() == reflect.Ptr && !field.IsNil() {
+		field = field.Elem()
+	}
+	return f.checkEnum(field, directive)
+}
+
+// checkEnum reports an error unless value equals one of the allowed values of the directive,
+// each converted to the value's type so that, for instance, "1_000" allows 1000.
+func (f *filler) checkEnum(value reflect.Value, directive Directive) error {
+	for _, option := range directive.Args {
+		allowed, err := f.convertString(option, value.Type())
+		if err != nil {
+			return err
+		}
+		if value.Type().Comparable() && allowed.Interface() == value.Interface() {
+			return nil
+		}
+	}
+	return fmt.Errorf(ErrEnumValue, value.Interface(), strings.Join(directive.Args, ","))
+}
+
+// =====================================================
+// Random values
+// =====================================================
+
+// maxUniqueAttempts bounds the retries made to find a value not yet generated by rand:unique.
+const maxUniqueAttempts = 100
+
+// randomStringLength is the length of the strings generated by rand:unique.
+const randomStringLength = 16
+
+const alphanumericChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// randomCharsets are the character sets of rand:str:N:charset, alnum being the default.
+var randomCharsets = map[string]string{
+	"alnum": alphanumericChars,
+	"hex":   "0123456789abcdef",
+}
+
+// setRandomValue handles "rand:<mode>" tags.
+// "rand:unique" generates a random value that no other rand:unique field received during the
+// same fill call, so elements generated by fill:N get distinct values.
+// "rand:str:N" generates a random string of N characters, alphanumeric unless a charset is
+// given, as in "rand:str:8:hex".
+func (f *filler) setRandomValue(field reflect.Value, mode string) error {
+	if args, found := strings.CutPrefix(mode, "str:"); found {
+		return f.setRandomString(field, args)
+	}
+	if mode != "unique" {
+		return fmt.Errorf(ErrUnknownRandMode, mode)
+	}
+
+	if f.uniqueValues == nil {
+		f.uniqueValues = make(map[string]bool)
+	}
+
+	for attempt := 0; attempt < maxUniqueAttempts; attempt++ {
+		value, err := f.randomValue(field.Type())
+		if err != nil {
+			return err
+		}
+
+		key := fmt.Sprintf("%s:%v", field.Type(), value.Interface())
+		if !f.uniqueValues[key] {
+			f.uniqueValues[key] = true
+			field.Set(value)
+			return nil
+		}
+	}
+	return fmt.Errorf(ErrUniqueExhausted, field.Type(), maxUniqueAttempts)
+}
+
+func (f *filler) setRandomString(field reflect.Value, args string) error {
+	if field.Kind() != reflect.String {
+		return withKind(ErrUnsupported, fmt.Errorf(ErrUnsupportedRandType, field.Type()))
+	}
+
+	lengthArg, charset, found := strings.Cut(args, ":")
+	if !found {
+		charset = "alnum"
+	}
+	chars, exists := randomCharsets[charset]
+	if !exists {
+		return fmt.Errorf(ErrRandCharset, charset)
+	}
+	length, err := strconv.Atoi(strings.TrimSpace(lengthArg))
+	if err != nil || length < 0 {
+		return fmt.Errorf(ErrRandLength, lengthArg)
+	}
+
+	field.SetString(f.randomString(length, chars))
+	return nil
+}
+
+// randomString generates a string of length characters picked from chars.
+func (f *filler) randomString(length int, chars string) string {
+	r := f.random()
+	b := make([]byte, length)
+	for i := range b {
+		b[i] = chars[r.Intn(len(chars))]
+	}
+	return string(b)
+}
+
+// randomValue generates a random value of a string or numeric type.
+// Integers are non-negative and fit the type, strings are alphanumeric.
+func (f *filler) randomValue(t reflect.Type) (reflect.Value, error) {
+	r := f.random()
+	switch t.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(f.randomString(randomStringLength, alphanumericChars)).Convert(t), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return reflect.ValueOf(r.Int63() >> (64 - t.Bits())).Convert(t), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return reflect.ValueOf(r.Uint64() >> (64 - t.Bits())).Convert(t), nil
+	case reflect.Float32, reflect.Float64:
+		return reflect.ValueOf(r.Float64()).Convert(t), nil
+	}
+	return reflect.Value{}, withKind(ErrUnsupported, fmt.Errorf(ErrUnsupportedRandType, t))
+}
+
+// Word lists of the fake: categories
+var (
+	fakeFirstNames = []string{"Alice", "Bruno", "Chloe", "Diego", "Emma", "Farid", "Grace", "Hiro", "Ines", "Jonas"}
+	fakeLastNames  = []string{"Almeida", "Becker", "Chen", "Dubois", "Evans", "Fischer", "Garcia", "Haddad", "Ito", "Jensen"}
+	fakeCities     = []string{"Lisbon", "Berlin", "Toronto", "Osaka", "Nairobi", "Lima", "Oslo", "Porto", "Denver", "Seoul"}
+)
+
+// setFakeValue fills a string field with a realistic-looking value of the "fake:<category>"
+// category: name, email or city. Values come from the random generator of the fill call, so
+// they are reproducible with WithSeed.
+func (f *filler) setFakeValue(field reflect.Value, category string) error {
+	if field.Kind() != reflect.String {
+		return withKind(ErrUnsupported, fmt.Errorf(ErrFakeType, field.Type()))
+	}
+
+	r := f.random()
+	first := fakeFirstNames[r.Intn(len(fakeFirstNames))]
+	last := fakeLastNames[r.Intn(len(fakeLastNames))]
+
+	switch category {
+	case "name":
+		field.SetString(first + " " + last)
+	case "email":
+		field.SetString(strings.ToLower(first+"."+last) + "@example.com")
+	case "city":
+		field.SetString(fakeCities[r.Intn(len(fakeCities))])
+	default:
+		return fmt.Errorf(ErrUnknownFake, category)
+	}
+	return nil
+}
+
+// random returns the random generator of the fill call, creating it on first use.
+func (f *filler) random() *rand.Rand {
+	if f.rand == nil {
+		seed := time.Now().UnixNano()
+		if f.opts.seeded {
+			seed = f.opts.seed
+		}
+		source := f.opts.randSource
+		if source == nil {
+			source = rand.NewSource(seed)
+		}
+		f.rand = rand.New(source)
+	}
+	return f.rand
+}
+
+// newUUID generates an RFC 4122 version 4 UUID string from the random generator of the fill
+// call, so uuid tags are reproducible with WithSeed. Types such as uuid.UUID parse it through
+// encoding.TextUnmarshaler.
+func (f *filler) newUUID() string {
+	var b [16]byte
+	f.random().Read(b[:])
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func isInteger(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+func (f *filler) setStructValue(field reflect.Value, directive Directive) error {
+	tag := directive.Raw
+	if isTimeType(field.Type()) {
+		return f.setTimeValue(field, directive)
+	}
+
+	switch field.Type() {
+	case reflect.TypeOf(big.Int{}):
+		return setBigIntValue(field, tag)
+	case reflect.TypeOf(big.Float{}):
+		return setBigFloatValue(field, tag)
+	case reflect.TypeOf(url.URL{}):
+		return setURLValue(field, tag)
+	}
+
+	if isTextUnmarshaler(field.Type()) {
+		return setTextValue(field, tag)
+	}
+	if isScanner(field.Type()) {
+		return setScannedValue(field, tag)
+	}
+	return withKind(ErrUnsupported, fmt.Errorf(ErrUnsupportedStruct, field.Type()))
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// isTimeType reports whether t is time.Time or a type defined on it, such as type Timestamp time.Time.
+func isTimeType(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.ConvertibleTo(timeType)
+}
+
+// setTimeValue fills a time.Time field, or one of a type defined on time.Time, from the tag.
+func (f *filler) setTimeValue(field reflect.Value, directive Directive) error {
+	// Support "tz:Zone:value" syntax for times in a specific location
+	if directive.Kind == DirectiveTimeZone {
+		t, err := f.parseTimeInZone(directive.Name, directive.Value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(t).Convert(field.Type()))
+		return nil
+	}
+
+	t, err := f.parseTime(directive.Raw)
+	if err != nil {
+		return withKind(ErrConversion, err)
+	}
+	field.Set(reflect.ValueOf(t).Convert(field.Type()))
+	return nil
+}
+
+// parseTime parses an RFC3339 time, or one in the WithTimeLayouts layouts when set, or one of
+// the keywords "now" (the current time, or the WithNow clock) and "unix:seconds" (a Unix
+// timestamp, in UTC).
+func (f *filler) parseTime(tag string) (time.Time, error) {
+	if tag == TagNow {
+		if f.opts.now != nil {
+			return f.opts.now(), nil
+		}
+		return time.Now(), nil
+	}
+
+	if strings.HasPrefix(tag, TagUnix) {
+		seconds, err := parseInt(strings.TrimPrefix(tag, TagUnix), 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf(ErrUnixTime, tag, err)
+		}
+		return time.Unix(seconds, 0).UTC(), nil
 	}
 
-	field.Set(m)
+	if len(f.opts.timeLayouts) == 0 {
+		return time.Parse(time.RFC3339, tag)
+	}
+
+	// Try each WithTimeLayouts layout in order, reporting the error of the last one
+	var err error
+	for _, layout := range f.opts.timeLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, tag); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// wallClockLayouts are the layouts accepted after a tz: zone, interpreted in that zone.
+var wallClockLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// parseTimeInZone parses a time in the named zone. RFC3339, now and unix: values keep their
+// instant and are converted to the zone; wall-clock values are interpreted in the zone.
+func (f *filler) parseTimeInZone(zone, value string) (time.Time, error) {
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf(ErrTimeZone, err)
+	}
+
+	if t, err := f.parseTime(value); err == nil {
+		return t.In(loc), nil
+	}
+	for _, layout := range wallClockLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf(ErrTimeInZone, value, zone)
+}
+
+func setBigIntValue(field reflect.Value, tag string) error {
+	n, ok := new(big.Int).SetString(tag, 10)
+	if !ok {
+		return withKind(ErrConversion, fmt.Errorf(ErrBigNumber, tag, field.Type()))
+	}
+	field.Set(reflect.ValueOf(n).Elem())
 	return nil
 }
 
-func setPtrValue(field reflect.Value, tag string) error {
-	elemType := field.Type().Elem()
-	elem := reflect.New(elemType).Elem()
-
-	// Create a dummy StructField for recursive call
-	dummyField := reflect.StructField{Type: elemType}
-	err := setFieldValue(elem, dummyField, tag)
-	if err != nil {
-		return err
+func setBigFloatValue(field reflect.Value, tag string) error {
+	f, ok := new(big.Float).SetString(tag)
+	if !ok {
+		return withKind(ErrConversion, fmt.Errorf(ErrBigNumber, tag, field.Type()))
 	}
-
-	field.Set(elem.Addr())
+	field.Set(reflect.ValueOf(f).Elem())
 	return nil
 }
 
-// setPrimitiveValue handles all primitive types (int, uint, float, string, bool)
-func setPrimitiveValue(field reflect.Value, tag string) error {
-	convertedValue, err := convertStringToType(tag, field.Type())
+func setURLValue(field reflect.Value, tag string) error {
+	u, err := url.Parse(tag)
 	if err != nil {
-		return err
+		return withKind(ErrConversion, fmt.Errorf(ErrStringConvert, tag, field.Type(), err))
 	}
-	field.Set(convertedValue)
+	field.Set(reflect.ValueOf(u).Elem())
 	return nil
 }
 
-func setStructValue(field reflect.Value, tag string) error {
-	if field.Type() == reflect.TypeOf(time.Time{}) {
-		return setTimeValue(field, tag)
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+func isTextUnmarshaler(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+func isScanner(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(scannerType)
+}
+
+// setScannedValue fills types implementing sql.Scanner, such as sql.NullString, by scanning the tag.
+func setScannedValue(field reflect.Value, tag string) error {
+	value := reflect.New(field.Type())
+	if err := value.Interface().(sql.Scanner).Scan(tag); err != nil {
+		return withKind(ErrConversion, fmt.Errorf(ErrStringConvert, tag, field.Type(), err))
 	}
-	return fmt.Errorf(ErrUnsupportedStruct, field.Type())
+	field.Set(value.Elem())
+	return nil
 }
 
-func setTimeValue(field reflect.Value, tag string) error {
-	t, err := time.Parse(time.RFC3339, tag)
+// setTextValue fills types implementing encoding.TextUnmarshaler by passing them the tag.
+func setTextValue(field reflect.Value, tag string) error {
+	value, err := unmarshalText(tag, field.Type())
 	if err != nil {
 		return err
 	}
-	field.Set(reflect.ValueOf(t))
+	field.Set(value)
 	return nil
 }
 
-func callFactoryFunction(field reflect.Value, factoryTag string) (err error) {
-	// Recover from panics in factory functions
+func unmarshalText(s string, t reflect.Type) (reflect.Value, error) {
+	value := reflect.New(t)
+	if err := value.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+		return reflect.Value{}, withKind(ErrConversion, fmt.Errorf(ErrStringConvert, s, t, err))
+	}
+	return value.Elem(), nil
+}
+
+func (f *filler) callFactoryFunction(field reflect.Value, factoryName string, args []string) (err error) {
 	defer func() {
-		if r := recover(); r != nil {
-			err = fmt.Errorf(ErrFactoryPanic, r)
+		if err != nil {
+			err = withKind(ErrFactory, err)
 		}
 	}()
 
-	factoryName, args := parseFactoryTag(factoryTag)
+	// Recover from panics in factory functions, unless they should reach the caller
+	if !f.opts.passFactoryPanics {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf(ErrFactoryPanic, r)
+			}
+		}()
+	}
+
 	funcValue, funcType, err := getAndValidateFactoryFunction(factoryName)
 	if err != nil {
 		return err
 	}
 
-	callArgs, err := prepareFactoryArgs(args, funcType, factoryName)
+	callArgs, err := f.prepareFactoryArgs(args, funcType, factoryName)
 	if err != nil {
 		return err
 	}
 
-	result, err := callAndValidateFactory(funcValue, callArgs, factoryName, field.Type())
+	result, err := f.callAndValidateFactory(funcValue, callArgs, factoryName, field.Type())
 	if err != nil {
 		return err
 	}
@@ -534,41 +3690,107 @@ func parseFactoryTag(factoryTag string) (string, []string) {
 }
 
 func getAndValidateFactoryFunction(factoryName string) (reflect.Value, reflect.Type, error) {
-	funcValue := reflect.ValueOf(getFactoryFunction(factoryName))
-	if !funcValue.IsValid() {
+	factory, exists := getFactoryFunction(factoryName)
+	if !exists {
 		return reflect.Value{}, nil, fmt.Errorf(ErrFactoryNotFound, factoryName)
 	}
-	return funcValue, funcValue.Type(), nil
+	return factory.value, factory.funcType, nil
 }
 
-func prepareFactoryArgs(args []string, funcType reflect.Type, factoryName string) ([]reflect.Value, error) {
-	// Validate argument count
-	if len(args) != funcType.NumIn() {
-		return nil, fmt.Errorf(ErrFactoryArgCount, factoryName, funcType.NumIn(), len(args))
+func (f *filler) prepareFactoryArgs(args []string, funcType reflect.Type, factoryName string) ([]reflect.Value, error) {
+	// A leading context.Context parameter receives the fill context instead of a tag argument
+	var callArgs []reflect.Value
+	if funcType.NumIn() > 0 && funcType.In(0) == contextType {
+		callArgs = append(callArgs, reflect.ValueOf(&f.ctx).Elem())
+	}
+	offset := len(callArgs)
+
+	// Validate argument count, allowing missing trailing arguments with WithFactoryDefaults
+	expected := funcType.NumIn() - offset
+	if len(args) > expected || (len(args) < expected && !f.opts.factoryDefaults) {
+		return nil, fmt.Errorf(ErrFactoryArgCount, factoryName, expected, len(args))
 	}
 
 	// Prepare arguments
-	callArgs := make([]reflect.Value, len(args))
 	for i, arg := range args {
-		paramType := funcType.In(i)
-		argValue, err := convertStringToType(arg, paramType)
+		paramType := funcType.In(i + offset)
+		argValue, err := f.convertFactoryArg(arg, paramType)
 		if err != nil {
 			return nil, fmt.Errorf(ErrFactoryArgConvert, factoryName, i, err)
 		}
-		callArgs[i] = argValue
+		callArgs = append(callArgs, argValue)
+	}
+	for i := len(callArgs); i < funcType.NumIn(); i++ {
+		callArgs = append(callArgs, reflect.Zero(funcType.In(i)))
 	}
 	return callArgs, nil
 }
 
-func callAndValidateFactory(funcValue reflect.Value, callArgs []reflect.Value, factoryName string, fieldType reflect.Type) (reflect.Value, error) {
-	// Call the factory function
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// factoryArgSeparator separates the elements of slice and array factory arguments, e.g. "1|2|3".
+const factoryArgSeparator = "|"
+
+// convertFactoryArg converts a factory argument, splitting slice and array arguments into elements.
+func (f *filler) convertFactoryArg(arg string, paramType reflect.Type) (reflect.Value, error) {
+	kind := paramType.Kind()
+	if (kind != reflect.Slice && kind != reflect.Array) || hasTextConversion(paramType) {
+		return f.convertString(arg, paramType)
+	}
+
+	var parts []string
+	if arg != "" {
+		parts = strings.Split(arg, factoryArgSeparator)
+	}
+
+	slice := reflect.MakeSlice(reflect.SliceOf(paramType.Elem()), len(parts), len(parts))
+	for i, part := range parts {
+		elemValue, err := f.convertString(strings.TrimSpace(part), paramType.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		slice.Index(i).Set(elemValue)
+	}
+
+	if kind == reflect.Slice {
+		return slice.Convert(paramType), nil
+	}
+	if slice.Len() != paramType.Len() {
+		return reflect.Value{}, fmt.Errorf(ErrArrayLength, paramType.Len(), slice.Len())
+	}
+	array := reflect.New(paramType).Elem()
+	reflect.Copy(array, slice)
+	return array, nil
+}
+
+func (f *filler) callAndValidateFactory(funcValue reflect.Value, callArgs []reflect.Value, factoryName string, fieldType reflect.Type) (reflect.Value, error) {
+	// Call the factory function, ignoring extra return values with WithFactoryIgnoreExtraReturns
 	results := funcValue.Call(callArgs)
-	if len(results) != 1 {
+	if len(results) == 0 || (len(results) > 1 && !f.opts.ignoreExtraReturns) {
 		return reflect.Value{}, fmt.Errorf(ErrFactoryReturnCount, factoryName)
 	}
 
+	// A trailing error, as in (T, error), is never ignored
+	if last := results[len(results)-1]; len(results) > 1 && last.Type() == errorType && !last.IsNil() {
+		return reflect.Value{}, fmt.Errorf(ErrFactoryFailed, factoryName, last.Interface().(error))
+	}
+
 	result := results[0]
+
+	// Interface returns, such as interface{}, are checked against their dynamic value
+	if result.Kind() == reflect.Interface && !result.Type().AssignableTo(fieldType) {
+		if result.IsNil() {
+			return reflect.Value{}, fmt.Errorf(ErrFactoryReturnNil, factoryName, fieldType)
+		}
+		result = result.Elem()
+	}
+
 	if !result.Type().AssignableTo(fieldType) {
+		if f.opts.convertibleReturns && canConvert(result.Type(), fieldType) {
+			return result.Convert(fieldType), nil
+		}
 		return reflect.Value{}, fmt.Errorf(ErrFactoryReturnType, factoryName, result.Type(), fieldType)
 	}
 	return result, nil
@@ -578,19 +3800,188 @@ func callAndValidateFactory(funcValue reflect.Value, callArgs []reflect.Value, f
 // Factory registry and public API
 // =====================================================
 
-// Factory registry
-var factoryRegistry = make(map[string]interface{})
+// registeredFactory caches the reflection data of a factory function when it is registered,
+// so that factory-heavy fills do not recompute it on every call.
+type registeredFactory struct {
+	value    reflect.Value
+	funcType reflect.Type
+}
+
+// Factory registry, guarded by factoryMu so factories can be registered while filling
+var (
+	factoryMu       sync.RWMutex
+	factoryRegistry = make(map[string]registeredFactory)
+)
 
-func getFactoryFunction(name string) interface{} {
-	if fn, exists := factoryRegistry[name]; exists {
-		return fn
-	}
+func getFactoryFunction(name string) (registeredFactory, bool) {
+	factoryMu.RLock()
+	defer factoryMu.RUnlock()
 
 	// Factory functions must be registered before use
+	factory, exists := factoryRegistry[name]
+	return factory, exists
+}
+
+// =====================================================
+// Interface implementations
+// =====================================================
+
+// Interface implementation registry, guarded by implementationMu
+var (
+	implementationMu       sync.RWMutex
+	implementationRegistry = make(map[reflect.Type]reflect.Type)
+)
+
+func getImplementation(iface reflect.Type) (reflect.Type, bool) {
+	implementationMu.RLock()
+	defer implementationMu.RUnlock()
+	implType, exists := implementationRegistry[iface]
+	return implType, exists
+}
+
+// newImplementation creates a zero value of the implementation registered for iface, along
+// with the addressable struct inside it that should be filled.
+func newImplementation(iface reflect.Type) (reflect.Value, reflect.Value, error) {
+	implType, exists := getImplementation(iface)
+	if !exists {
+		return reflect.Value{}, reflect.Value{}, fmt.Errorf(ErrNoImplementation, iface)
+	}
+	if !implType.Implements(iface) {
+		return reflect.Value{}, reflect.Value{}, fmt.Errorf(ErrImplementationType, implType, iface)
+	}
+
+	switch {
+	case implType.Kind() == reflect.Struct:
+		impl := reflect.New(implType).Elem()
+		return impl, impl, nil
+	case implType.Kind() == reflect.Ptr && implType.Elem().Kind() == reflect.Struct:
+		impl := reflect.New(implType.Elem())
+		return impl, impl.Elem(), nil
+	default:
+		return reflect.Value{}, reflect.Value{}, fmt.Errorf(ErrImplementationType, implType, iface)
+	}
+}
+
+// fillInterface fills an interface field tagged with fill. A nil interface is set to a new
+// value of the registered implementation, or left nil when none is registered; one already
+// holding a struct pointer is filled in place.
+func (f *filler) fillInterface(field reflect.Value, variants []string) error {
+	if !field.IsNil() {
+		current := field.Elem()
+		if current.Kind() == reflect.Ptr && !current.IsNil() && current.Elem().Kind() == reflect.Struct {
+			return f.fillStructWithVariant(current.Elem(), variants)
+		}
+		return nil
+	}
+	if _, exists := getImplementation(field.Type()); !exists {
+		return nil
+	}
+
+	impl, target, err := newImplementation(field.Type())
+	if err != nil {
+		return err
+	}
+	if err := f.fillStructWithVariant(target, variants); err != nil {
+		return err
+	}
+	field.Set(impl)
+	return nil
+}
+
+// =====================================================
+// Value provider
+// =====================================================
+
+// Value provider used by provider:key tags, guarded by providerMu
+var (
+	providerMu    sync.RWMutex
+	valueProvider func(key string) (string, error)
+)
+
+func getValueProvider() func(key string) (string, error) {
+	providerMu.RLock()
+	defer providerMu.RUnlock()
+	return valueProvider
+}
 
+func (f *filler) setProvidedValue(field reflect.Value, key string) error {
+	provider := getValueProvider()
+	if provider == nil {
+		return fmt.Errorf(ErrNoProvider, key)
+	}
+
+	value, err := provider(key)
+	if err != nil {
+		return fmt.Errorf(ErrProvider, key, err)
+	}
+
+	return f.setLiteralValue(field, value)
+}
+
+// =====================================================
+// Object registry
+// =====================================================
+
+// Objects registered with RegisterObject, used by ref:name tags, guarded by objectMu
+var (
+	objectMu       sync.RWMutex
+	objectRegistry = make(map[string]reflect.Value)
+)
+
+func getObject(name string) (reflect.Value, bool) {
+	objectMu.RLock()
+	defer objectMu.RUnlock()
+	object, exists := objectRegistry[name]
+	return object, exists
+}
+
+func setRefValue(field reflect.Value, name string) error {
+	object, exists := getObject(name)
+	if !exists {
+		return fmt.Errorf(ErrUnknownRef, name)
+	}
+	if !object.Type().AssignableTo(field.Type()) {
+		return fmt.Errorf(ErrRefType, name, object.Type(), field.Type())
+	}
+
+	field.Set(object)
 	return nil
 }
 
+// =====================================================
+// Preset selection
+// =====================================================
+
+// setOneOfValue fills the field with the oneof: option at the current element index, so the
+// elements of a fill:N or variants: slice take the options in order. Outside a slice the
+// first option is used.
+func (f *filler) setOneOfValue(field reflect.Value, directive Directive) error {
+	if f.elementIndex >= len(directive.Args) {
+		return fmt.Errorf(ErrOneOfIndex, directive.Raw, f.elementIndex)
+	}
+	return f.setFieldValue(field, reflect.StructField{}, directive.Args[f.elementIndex])
+}
+
+// =====================================================
+// Templates
+// =====================================================
+
+// setTemplateValue executes a tmpl: tag against the template data option and fills the
+// field from the rendered string like any other tag value.
+func (f *filler) setTemplateValue(field reflect.Value, text string) error {
+	tmpl, err := template.New("testfill").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return fmt.Errorf(ErrTemplate, text, err)
+	}
+
+	var rendered strings.Builder
+	if err := tmpl.Execute(&rendered, f.opts.templateData); err != nil {
+		return fmt.Errorf(ErrTemplate, text, err)
+	}
+
+	return f.setLiteralValue(field, rendered.String())
+}
+
 // =====================================================
 // Type conversion utilities
 // ==============================================
@@ -599,40 +3990,287 @@ type typeConverter func(string) (interface{}, error)
 
 var typeConverters = map[reflect.Kind]typeConverter{
 	reflect.String:  func(s string) (interface{}, error) { return s, nil },
-	reflect.Bool:    func(s string) (interface{}, error) { return strconv.ParseBool(s) },
-	reflect.Int:     func(s string) (interface{}, error) { return strconv.ParseInt(s, 10, 64) },
-	reflect.Int8:    func(s string) (interface{}, error) { return strconv.ParseInt(s, 10, 8) },
-	reflect.Int16:   func(s string) (interface{}, error) { return strconv.ParseInt(s, 10, 16) },
-	reflect.Int32:   func(s string) (interface{}, error) { return strconv.ParseInt(s, 10, 32) },
-	reflect.Int64:   func(s string) (interface{}, error) { return strconv.ParseInt(s, 10, 64) },
-	reflect.Uint:    func(s string) (interface{}, error) { return strconv.ParseUint(s, 10, 64) },
-	reflect.Uint8:   func(s string) (interface{}, error) { return strconv.ParseUint(s, 10, 8) },
-	reflect.Uint16:  func(s string) (interface{}, error) { return strconv.ParseUint(s, 10, 16) },
-	reflect.Uint32:  func(s string) (interface{}, error) { return strconv.ParseUint(s, 10, 32) },
-	reflect.Uint64:  func(s string) (interface{}, error) { return strconv.ParseUint(s, 10, 64) },
-	reflect.Float32: func(s string) (interface{}, error) { return strconv.ParseFloat(s, 32) },
-	reflect.Float64: func(s string) (interface{}, error) { return strconv.ParseFloat(s, 64) },
+	reflect.Bool:    func(s string) (interface{}, error) { return parseBool(s) },
+	reflect.Int:     func(s string) (interface{}, error) { return parseInt(s, 64) },
+	reflect.Int8:    func(s string) (interface{}, error) { return parseInt(s, 8) },
+	reflect.Int16:   func(s string) (interface{}, error) { return parseInt(s, 16) },
+	reflect.Int32:   func(s string) (interface{}, error) { return parseInt(s, 32) },
+	reflect.Int64:   func(s string) (interface{}, error) { return parseInt(s, 64) },
+	reflect.Uint:    func(s string) (interface{}, error) { return parseUint(s, 64) },
+	reflect.Uint8:   func(s string) (interface{}, error) { return parseUint(s, 8) },
+	reflect.Uint16:  func(s string) (interface{}, error) { return parseUint(s, 16) },
+	reflect.Uint32:  func(s string) (interface{}, error) { return parseUint(s, 32) },
+	reflect.Uint64:  func(s string) (interface{}, error) { return parseUint(s, 64) },
+	reflect.Uintptr: func(s string) (interface{}, error) { return parseUint(s, 64) },
+	reflect.Float32: func(s string) (interface{}, error) { return parseFloat(s, 32) },
+	reflect.Float64: func(s string) (interface{}, error) { return parseFloat(s, 64) },
 }
 
 func convertStringToType(arg string, targetType reflect.Type) (reflect.Value, error) {
+	if converter, exists := getConverter(targetType); exists {
+		return convertWithRegistered(arg, targetType, converter)
+	}
+
+	// Durations accept Go duration strings such as "1h30m" or "-5s", besides plain nanoseconds
+	if targetType == durationType {
+		if d, err := time.ParseDuration(arg); err == nil {
+			return reflect.ValueOf(d), nil
+		}
+	}
+
+	// Months and weekdays accept their English names, such as "January" or "monday", besides numbers
+	if value, exists := calendarNames[targetType][strings.ToLower(arg)]; exists {
+		return value, nil
+	}
+
+	// Types implementing encoding.TextUnmarshaler, such as decimal types, parse the value themselves
+	if isTextUnmarshaler(targetType) {
+		return unmarshalText(arg, targetType)
+	}
+
 	converter, exists := typeConverters[targetType.Kind()]
 	if !exists {
-		return reflect.Value{}, fmt.Errorf(ErrUnsupportedParam, targetType.Kind())
+		return reflect.Value{}, withKind(ErrUnsupported, fmt.Errorf(ErrUnsupportedParam, targetType.Kind()))
 	}
 
 	val, err := converter(arg)
 	if err != nil {
-		return reflect.Value{}, fmt.Errorf(ErrStringConvert, arg, targetType.Kind(), err)
+		return reflect.Value{}, withKind(ErrConversion, fmt.Errorf(ErrStringConvert, arg, targetType.Kind(), err))
 	}
 
 	return reflect.ValueOf(val).Convert(targetType), nil
 }
 
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// calendarNames maps the lowercase names of time.Month and time.Weekday values to the values.
+var calendarNames = func() map[reflect.Type]map[string]reflect.Value {
+	months := make(map[string]reflect.Value)
+	for m := time.January; m <= time.December; m++ {
+		months[strings.ToLower(m.String())] = reflect.ValueOf(m)
+	}
+	weekdays := make(map[string]reflect.Value)
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		weekdays[strings.ToLower(d.String())] = reflect.ValueOf(d)
+	}
+	return map[reflect.Type]map[string]reflect.Value{
+		reflect.TypeOf(time.Month(0)):   months,
+		reflect.TypeOf(time.Weekday(0)): weekdays,
+	}
+}()
+
+// boolAliases maps the lowercase words accepted by bool tags, besides those of strconv.ParseBool.
+var boolAliases = map[string]bool{
+	"yes": true, "y": true, "on": true,
+	"no": false, "n": false, "off": false,
+}
+
+// parseBool parses a bool, also accepting yes/no, y/n and on/off in any case.
+func parseBool(s string) (bool, error) {
+	normalized := strings.ToLower(strings.TrimSpace(s))
+	if b, ok := boolAliases[normalized]; ok {
+		return b, nil
+	}
+	return strconv.ParseBool(normalized)
+}
+
+// convertString converts a tag value like convertStringToType, restricting bools
+// to strconv.ParseBool when WithStrictBool is set.
+func (f *filler) convertString(arg string, targetType reflect.Type) (reflect.Value, error) {
+	// Types defined on time.Time lose its text methods, so they are parsed like time.Time tags
+	if _, exists := getConverter(targetType); !exists && isTimeType(targetType) && targetType != timeType {
+		t, err := f.parseTime(arg)
+		if err != nil {
+			return reflect.Value{}, withKind(ErrConversion, fmt.Errorf(ErrStringConvert, arg, targetType, err))
+		}
+		return reflect.ValueOf(t).Convert(targetType), nil
+	}
+	if f.opts.strictBool && targetType.Kind() == reflect.Bool && !hasTextConversion(targetType) {
+		b, err := strconv.ParseBool(arg)
+		if err != nil {
+			return reflect.Value{}, withKind(ErrConversion, fmt.Errorf(ErrStringConvert, arg, targetType.Kind(), err))
+		}
+		return reflect.ValueOf(b).Convert(targetType), nil
+	}
+	return convertStringToType(arg, targetType)
+}
+
+// Converter registry, guarded by converterMu
+var (
+	converterMu       sync.RWMutex
+	converterRegistry = make(map[reflect.Type]func(string) (interface{}, error))
+)
+
+func getConverter(t reflect.Type) (func(string) (interface{}, error), bool) {
+	converterMu.RLock()
+	defer converterMu.RUnlock()
+	converter, exists := converterRegistry[t]
+	return converter, exists
+}
+
+func convertWithRegistered(arg string, targetType reflect.Type, converter func(string) (interface{}, error)) (reflect.Value, error) {
+	val, err := converter(arg)
+	if err != nil {
+		return reflect.Value{}, withKind(ErrConversion, fmt.Errorf(ErrStringConvert, arg, targetType, err))
+	}
+
+	result := reflect.ValueOf(val)
+	if !result.IsValid() || !result.Type().ConvertibleTo(targetType) {
+		return reflect.Value{}, withKind(ErrConversion, fmt.Errorf(ErrConverterType, targetType, val))
+	}
+	return result.Convert(targetType), nil
+}
+
+// canConvert reports whether values of type from convert to type to, excluding conversions of
+// numbers to strings, which yield the character with that code rather than the digits.
+func canConvert(from, to reflect.Type) bool {
+	return from.ConvertibleTo(to) && (to.Kind() != reflect.String || from.Kind() == reflect.String)
+}
+
+// hasTextConversion reports whether values of type t are parsed from text by a registered
+// converter, encoding.TextUnmarshaler or as a time rather than filled field by field.
+func hasTextConversion(t reflect.Type) bool {
+	_, exists := getConverter(t)
+	return exists || isTextUnmarshaler(t) || isTimeType(t)
+}
+
+func setConvertedValue(field reflect.Value, tag string) error {
+	convertedValue, err := convertStringToType(tag, field.Type())
+	if err != nil {
+		return err
+	}
+	field.Set(convertedValue)
+	return nil
+}
+
+// numberSuffixes maps the human-readable suffixes accepted by numeric tags to their multipliers.
+var numberSuffixes = map[byte]int64{
+	'k': 1_000,
+	'M': 1_000_000,
+	'G': 1_000_000_000,
+}
+
+// splitNumber strips digit separators ("1_000") and a trailing k/M/G suffix ("10k"),
+// returning the plain number and the multiplier the suffix stands for.
+func splitNumber(s string) (string, int64) {
+	s = strings.ReplaceAll(s, "_", "")
+	if len(s) > 0 {
+		if multiplier, exists := numberSuffixes[s[len(s)-1]]; exists {
+			return s[:len(s)-1], multiplier
+		}
+	}
+	return s, 1
+}
+
+// numberError reports parsing errors against the number as written in the tag.
+func numberError(err error, original string) error {
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		numErr.Num = original
+	}
+	return err
+}
+
+// numberBase returns 0, letting strconv infer the base, for numbers with a 0x, 0o or 0b
+// prefix, and 10 otherwise so that leading zeros ("007") keep meaning decimal.
+func numberBase(number string) int {
+	digits := strings.TrimLeft(number, "+-")
+	if len(digits) > 2 && digits[0] == '0' && strings.ContainsRune("xXoObB", rune(digits[1])) {
+		return 0
+	}
+	return 10
+}
+
+func parseInt(s string, bitSize int) (int64, error) {
+	number, multiplier := splitNumber(s)
+	n, err := strconv.ParseInt(number, numberBase(number), bitSize)
+	if err != nil {
+		return 0, numberError(err, s)
+	}
+
+	result := n * multiplier
+	minValue, maxValue := int64(-1)<<(bitSize-1), int64(1)<<(bitSize-1)-1
+	if result/multiplier != n || result < minValue || result > maxValue {
+		return 0, &strconv.NumError{Func: "ParseInt", Num: s, Err: strconv.ErrRange}
+	}
+	return result, nil
+}
+
+func parseUint(s string, bitSize int) (uint64, error) {
+	number, multiplier := splitNumber(s)
+	n, err := strconv.ParseUint(number, numberBase(number), bitSize)
+	if err != nil {
+		return 0, numberError(err, s)
+	}
+
+	result := n * uint64(multiplier)
+	maxValue := uint64(1)<<(bitSize-1)<<1 - 1
+	if result/uint64(multiplier) != n || result > maxValue {
+		return 0, &strconv.NumError{Func: "ParseUint", Num: s, Err: strconv.ErrRange}
+	}
+	return result, nil
+}
+
+func parseFloat(s string, bitSize int) (float64, error) {
+	number, multiplier := splitNumber(s)
+	n, err := strconv.ParseFloat(number, bitSize)
+	if err != nil {
+		return 0, numberError(err, s)
+	}
+
+	result := n * float64(multiplier)
+	maxValue := math.MaxFloat64
+	if bitSize == 32 {
+		maxValue = math.MaxFloat32
+	}
+	if !math.IsInf(n, 0) && math.Abs(result) > maxValue {
+		return 0, &strconv.NumError{Func: "ParseFloat", Num: s, Err: strconv.ErrRange}
+	}
+	return result, nil
+}
+
+// =====================================================
+// CSV fixtures
+// =====================================================
+
+// setCSVColumns sets the fields of structValue from the CSV columns that match them.
+func (f *filler) setCSVColumns(structValue reflect.Value, header []string, row []string) error {
+	columns := make(map[string]string, len(header))
+	for i, column := range header {
+		columns[strings.TrimSpace(column)] = row[i]
+	}
+
+	structType := structValue.Type()
+	for i := 0; i < structValue.NumField(); i++ {
+		fieldValue := structValue.Field(i)
+		fieldType := structType.Field(i)
+
+		column := fieldType.Name
+		if name := fieldType.Tag.Get(TagCSV); name == "-" {
+			continue
+		} else if name != "" {
+			column = name
+		}
+
+		value, exists := columns[column]
+		if !fieldValue.CanSet() || !exists || value == "" {
+			continue
+		}
+
+		convertedValue, err := f.convertString(value, fieldType.Type)
+		if err != nil {
+			return f.fieldError(fieldType.Name, err)
+		}
+		fieldValue.Set(convertedValue)
+	}
+	return nil
+}
+
 // =====================================================
 // JSON unmarshal support
 // =====================================================
 
-func unmarshalJSON(field reflect.Value, jsonData string) error {
+func (f *filler) unmarshalJSON(field reflect.Value, jsonData string) error {
 	if field.Kind() == reflect.Ptr {
 		if jsonData == "null" {
 			field.Set(reflect.Zero(field.Type()))
@@ -644,26 +4282,70 @@ func unmarshalJSON(field reflect.Value, jsonData string) error {
 		}
 
 		// Unmarshal into the pointed value
-		return unmarshalJSONValue(field.Interface(), jsonData)
+		return f.unmarshalJSONValue(field.Interface(), jsonData)
 	}
 
 	// For non-pointer types, we need to unmarshal into the address
 	if field.CanAddr() {
-		return unmarshalJSONValue(field.Addr().Interface(), jsonData)
+		return f.unmarshalJSONValue(field.Addr().Interface(), jsonData)
 	}
 
 	// If we can't get the address, create a new value, unmarshal, and set
 	newValue := reflect.New(field.Type())
-	if err := unmarshalJSONValue(newValue.Interface(), jsonData); err != nil {
+	if err := f.unmarshalJSONValue(newValue.Interface(), jsonData); err != nil {
 		return err
 	}
 	field.Set(newValue.Elem())
 	return nil
 }
 
-func unmarshalJSONValue(target interface{}, jsonData string) error {
-	if err := json.Unmarshal([]byte(jsonData), target); err != nil {
+var rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+
+// setRawMessageValue stores the tag bytes in a json.RawMessage field, provided they are valid JSON.
+func setRawMessageValue(field reflect.Value, tag string) error {
+	if !json.Valid([]byte(tag)) {
+		return fmt.Errorf(ErrRawMessage, tag)
+	}
+	field.SetBytes([]byte(tag))
+	return nil
+}
+
+// setAnyValue fills an empty interface field from a literal tag. JSON values are unmarshaled
+// with encoding/json semantics, so "42" becomes float64(42), and other text is kept as a string.
+func (f *filler) setAnyValue(field reflect.Value, directive Directive) error {
+	if field.Type().NumMethod() != 0 || directive.Kind != DirectiveLiteral {
+		return withKind(ErrUnsupported, fmt.Errorf(ErrUnsupportedField, field.Kind()))
+	}
+
+	if json.Valid([]byte(directive.Raw)) {
+		return f.unmarshalJSON(field, directive.Raw)
+	}
+	field.Set(reflect.ValueOf(directive.Raw))
+	return nil
+}
+
+func (f *filler) unmarshalJSONValue(target interface{}, jsonData string) error {
+	if err := f.decodeJSON(target, jsonData); err != nil {
 		return fmt.Errorf(ErrJSONUnmarshal, err)
 	}
 	return nil
 }
+
+// decodeJSON behaves like json.Unmarshal, rejecting unknown object keys when
+// WithDisallowUnknownFields is enabled.
+func (f *filler) decodeJSON(target interface{}, jsonData string) error {
+	decoder := json.NewDecoder(strings.NewReader(jsonData))
+	if f.opts.disallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+
+	if err := decoder.Decode(target); err != nil {
+		return err
+	}
+
+	// Like json.Unmarshal, reject anything following the top-level value
+	if _, err := decoder.Token(); err != io.EOF {
+		return errors.New(ErrJSONTrailingData)
+	}
+	return nil
+}
@@ -1,7 +1,22 @@
 package testfill_test
 
 import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
+	"net"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -35,6 +50,82 @@ type CustomVO struct {
 	privateField string
 }
 
+type Shape interface {
+	Area() float64
+}
+
+type Circle struct {
+	Radius float64 `testfill:"2"`
+	Label  string  `testfill:"circle" testfill_large:"large circle"`
+}
+
+func (c *Circle) Area() float64 { return 3 * c.Radius * c.Radius }
+
+type Square struct {
+	Side float64 `testfill:"3"`
+}
+
+func (s Square) Area() float64 { return s.Side * s.Side }
+
+// Decimal is a stand-in for decimal types such as shopspring/decimal.Decimal.
+type Decimal struct {
+	units int64
+	scale int32
+}
+
+func (d *Decimal) UnmarshalText(text []byte) error {
+	intPart, fracPart, _ := strings.Cut(string(text), ".")
+	units, err := strconv.ParseInt(intPart+fracPart, 10, 64)
+	if err != nil {
+		return err
+	}
+	*d = Decimal{units: units, scale: int32(len(fracPart))}
+	return nil
+}
+
+// UUID is a stand-in for github.com/google/uuid.UUID.
+type UUID [16]byte
+
+func (u *UUID) UnmarshalText(text []byte) error {
+	decoded, err := hex.DecodeString(strings.ReplaceAll(string(text), "-", ""))
+	if err != nil || len(decoded) != len(u) {
+		return fmt.Errorf("invalid UUID %q", text)
+	}
+	copy(u[:], decoded)
+	return nil
+}
+
+// Money has no parsing method of its own and relies on a registered converter.
+type Money struct {
+	Cents int64
+}
+
+// Server sets defaults too complex for tags through testfill.Defaulter.
+type Server struct {
+	Host    string            `testfill:"localhost"`
+	Port    int               `testfill:"8080"`
+	Headers map[string]string `testfill:"x:1"`
+	Limits  []int
+}
+
+func (s *Server) TestfillDefault() {
+	s.Headers = map[string]string{"Accept": "application/json"}
+	s.Limits = []int{10, 100}
+	s.Port = 9090
+}
+
+// Box is a generic container used to check that generic instantiations are filled like any struct.
+type Box[T any] struct {
+	Value T      `testfill:"7" testfill_big:"700"`
+	Label string `testfill:"box"`
+}
+
+type Tree[T any] struct {
+	Root     Box[T]            `testfill:"fill"`
+	Children []Box[T]          `testfill:"fill:2"`
+	Named    map[string]Box[T] `testfill:"a:fill,b:big"`
+}
+
 func TestTestfill(t *testing.T) {
 	// Register factory with no arguments
 	testfill.RegisterFactory("NewCustomVO", func() CustomVO {
@@ -529,6 +620,29 @@ func TestTestfill(t *testing.T) {
 				require.Equal(t, expected, result.Value)
 			})
 
+			t.Run("struct pointer slice with fill syntax", func(t *testing.T) {
+				type VariantBar struct {
+					Integer int `testfill:"42" testfill_admin:"7"`
+				}
+				type StructPtrSliceTest struct {
+					Value    []*Bar        `testfill:"fill:2"`
+					Variants []*VariantBar `testfill:"variants:admin,default"`
+					Array    [1]*Bar       `testfill:"fill:1"`
+				}
+
+				result, err := testfill.Fill(StructPtrSliceTest{})
+				require.NoError(t, err)
+
+				expected := []*Bar{
+					{Integer: 42, String: "Olivie Smith"},
+					{Integer: 42, String: "Olivie Smith"},
+				}
+				require.Equal(t, expected, result.Value)
+				require.NotSame(t, result.Value[0], result.Value[1])
+				require.Equal(t, []*VariantBar{{Integer: 7}, {Integer: 42}}, result.Variants)
+				require.Equal(t, [1]*Bar{{Integer: 42, String: "Olivie Smith"}}, result.Array)
+			})
+
 			t.Run("invalid struct slice count", func(t *testing.T) {
 				type InvalidStructSlice struct {
 					Value []Bar `testfill:"fill:not_a_number"`
@@ -563,7 +677,7 @@ func TestTestfill(t *testing.T) {
 
 				result, err := testfill.Fill(SliceWithError{})
 
-				expectedError := "testfill: failed to set field Value: failed to fill slice element 0: testfill: failed to set field InvalidField: cannot convert \"not_a_number\" to int: strconv.ParseInt: parsing \"not_a_number\": invalid syntax"
+				expectedError := "testfill: failed to set field Value: failed to fill slice element 0: testfill: failed to set field Value[0].InvalidField: cannot convert \"not_a_number\" to int: strconv.ParseInt: parsing \"not_a_number\": invalid syntax"
 				require.EqualError(t, err, expectedError)
 				require.Equal(t, SliceWithError{}, result)
 			})
@@ -683,6 +797,28 @@ func TestTestfill(t *testing.T) {
 				require.Equal(t, expected, result.Value)
 			})
 
+			t.Run("struct pointer value map", func(t *testing.T) {
+				type VariantBar struct {
+					Integer int `testfill:"42" testfill_admin:"7"`
+				}
+				type StructPtrMapTest struct {
+					Fill     map[string]*Bar        `testfill:"first:fill,second:fill"`
+					Named    map[string]*VariantBar `testfill:"user:fill,root:admin"`
+					Variants map[string]*VariantBar `testfill:"variants:root=admin"`
+				}
+
+				result, err := testfill.Fill(StructPtrMapTest{})
+				require.NoError(t, err)
+
+				require.Equal(t, map[string]*Bar{
+					"first":  {Integer: 42, String: "Olivie Smith"},
+					"second": {Integer: 42, String: "Olivie Smith"},
+				}, result.Fill)
+				require.NotSame(t, result.Fill["first"], result.Fill["second"])
+				require.Equal(t, map[string]*VariantBar{"user": {Integer: 42}, "root": {Integer: 7}}, result.Named)
+				require.Equal(t, map[string]*VariantBar{"root": {Integer: 7}}, result.Variants)
+			})
+
 			t.Run("unsupported struct map key type", func(t *testing.T) {
 				type UnsupportedStructMap struct {
 					Value map[int]Bar `testfill:"1:fill,2:fill"`
@@ -745,7 +881,7 @@ func TestTestfill(t *testing.T) {
 
 				result, err := testfill.Fill(MapWithError{})
 
-				expectedError := "testfill: failed to set field Value: failed to fill map value for key key1: testfill: failed to set field InvalidField: cannot convert \"not_a_float\" to float64: strconv.ParseFloat: parsing \"not_a_float\": invalid syntax"
+				expectedError := "testfill: failed to set field Value: failed to fill map value for key key1: testfill: failed to set field Value[key1].InvalidField: cannot convert \"not_a_float\" to float64: strconv.ParseFloat: parsing \"not_a_float\": invalid syntax"
 				require.EqualError(t, err, expectedError)
 				require.Equal(t, MapWithError{}, result)
 			})
@@ -940,7 +1076,7 @@ func TestTestfill(t *testing.T) {
 
 			result, err := testfill.Fill(ContainerWithError{})
 
-			expectedError := "testfill: failed to fill nested struct Nested: testfill: failed to set field InvalidInt: cannot convert \"not_a_number\" to int: strconv.ParseInt: parsing \"not_a_number\": invalid syntax"
+			expectedError := "testfill: failed to fill nested struct Nested: testfill: failed to set field Nested.InvalidInt: cannot convert \"not_a_number\" to int: strconv.ParseInt: parsing \"not_a_number\": invalid syntax"
 			require.EqualError(t, err, expectedError)
 			require.Equal(t, ContainerWithError{}, result)
 		})
@@ -955,7 +1091,7 @@ func TestTestfill(t *testing.T) {
 
 			result, err := testfill.Fill(ContainerWithError{})
 
-			expectedError := "testfill: failed to fill nested struct pointer NestedPtr: testfill: failed to set field InvalidBool: cannot convert \"not_a_bool\" to bool: strconv.ParseBool: parsing \"not_a_bool\": invalid syntax"
+			expectedError := "testfill: failed to fill nested struct pointer NestedPtr: testfill: failed to set field NestedPtr.InvalidBool: cannot convert \"not_a_bool\" to bool: strconv.ParseBool: parsing \"not_a_bool\": invalid syntax"
 			require.EqualError(t, err, expectedError)
 			require.Equal(t, ContainerWithError{}, result)
 		})
@@ -1466,6 +1602,30 @@ func TestTestfill(t *testing.T) {
 			require.Equal(t, "New York", result.People[1].Address.City)
 		})
 
+		t.Run("nested structs with pinned variants", func(t *testing.T) {
+			type Address struct {
+				Street string `testfill:"123 Main St" testfill_work:"456 Office Blvd" testfill_manager:"1 Corner Office"`
+				City   string `testfill:"New York" testfill_work:"Boston" testfill_eu:"Paris"`
+			}
+
+			type Person struct {
+				Name    string   `testfill:"John" testfill_manager:"Jane"`
+				Office  Address  `testfill:"fill:variant=work"`
+				Home    Address  `testfill:"fill:variant=default"`
+				Branch  *Address `testfill:"fill:variant=eu, work"`
+				Current Address  `testfill:"fill"`
+			}
+
+			result, err := testfill.FillWithVariant(Person{}, "manager")
+			require.NoError(t, err)
+
+			require.Equal(t, "Jane", result.Name)
+			require.Equal(t, Address{Street: "456 Office Blvd", City: "Boston"}, result.Office)
+			require.Equal(t, Address{Street: "123 Main St", City: "New York"}, result.Home)
+			require.Equal(t, &Address{Street: "456 Office Blvd", City: "Paris"}, result.Branch)
+			require.Equal(t, Address{Street: "1 Corner Office", City: "New York"}, result.Current)
+		})
+
 		t.Run("single variant", func(t *testing.T) {
 			type User struct {
 				Name string `testfill:"John" testfill_admin:"Jane"`
@@ -1816,6 +1976,59 @@ func TestTestfill(t *testing.T) {
 			require.Equal(t, []string{"dev", "lead"}, result.Person.Tags)
 		})
 
+		t.Run("struct slice", func(t *testing.T) {
+			type Person struct {
+				Name string `json:"name"`
+				Age  int    `json:"age" testfill:"30"`
+			}
+			type TestStruct struct {
+				People []Person  `testfill:"unmarshal:[{\"name\":\"A\"},{\"name\":\"B\",\"age\":40}]"`
+				Pair   [2]Person `testfill:"unmarshal:[{\"name\":\"C\"},{\"name\":\"D\"}]"`
+			}
+
+			result, err := testfill.Fill(TestStruct{})
+			require.NoError(t, err)
+
+			require.Equal(t, []Person{{Name: "A"}, {Name: "B", Age: 40}}, result.People)
+			require.Equal(t, [2]Person{{Name: "C"}, {Name: "D"}}, result.Pair)
+		})
+
+		t.Run("struct-valued map", func(t *testing.T) {
+			type Address struct {
+				City string `json:"city"`
+			}
+			type Person struct {
+				Name    string  `json:"name"`
+				Address Address `json:"address"`
+			}
+			type TestStruct struct {
+				People map[string]Person   `testfill:"unmarshal:{\"alice\":{\"name\":\"Alice\",\"address\":{\"city\":\"NYC\"}},\"bob\":{\"name\":\"Bob\"}}"`
+				Ptrs   map[string]*Address `testfill:"unmarshal:{\"home\":{\"city\":\"Boston\"}}"`
+			}
+
+			result, err := testfill.Fill(TestStruct{})
+			require.NoError(t, err)
+
+			require.Equal(t, map[string]Person{
+				"alice": {Name: "Alice", Address: Address{City: "NYC"}},
+				"bob":   {Name: "Bob"},
+			}, result.People)
+			require.Equal(t, map[string]*Address{"home": {City: "Boston"}}, result.Ptrs)
+		})
+
+		t.Run("struct-valued map with invalid JSON", func(t *testing.T) {
+			type Person struct {
+				Name string `json:"name"`
+			}
+			type TestStruct struct {
+				People map[string]Person `testfill:"unmarshal:{\"alice\":\"Alice\"}"`
+			}
+
+			_, err := testfill.Fill(TestStruct{})
+
+			require.ErrorContains(t, err, "testfill: failed to set field People: failed to unmarshal JSON: json: cannot unmarshal string")
+		})
+
 		t.Run("preserves existing values", func(t *testing.T) {
 			type TestPreserve struct {
 				Value string  `testfill:"unmarshal:\"new\""`
@@ -1915,4 +2128,4954 @@ func TestTestfill(t *testing.T) {
 			require.Equal(t, InvalidTag{}, result)
 		})
 	})
+
+	t.Run("big numbers", func(t *testing.T) {
+		t.Run("big.Int fills default value", func(t *testing.T) {
+			type BigIntTest struct {
+				Value big.Int  `testfill:"123456789012345678901234567890"`
+				Ptr   *big.Int `testfill:"-42"`
+			}
+
+			result, err := testfill.Fill(BigIntTest{})
+			require.NoError(t, err)
+
+			expected, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+			require.Equal(t, 0, expected.Cmp(&result.Value))
+			require.NotNil(t, result.Ptr)
+			require.Equal(t, int64(-42), result.Ptr.Int64())
+		})
+
+		t.Run("big.Float fills default value", func(t *testing.T) {
+			type BigFloatTest struct {
+				Value big.Float  `testfill:"99.99"`
+				Ptr   *big.Float `testfill:"1.5"`
+			}
+
+			result, err := testfill.Fill(BigFloatTest{})
+			require.NoError(t, err)
+
+			require.Equal(t, "99.99", result.Value.Text('f', 2))
+			require.NotNil(t, result.Ptr)
+			require.Equal(t, "1.5", result.Ptr.Text('f', 1))
+		})
+
+		t.Run("does not fill when value is already filled", func(t *testing.T) {
+			type BigIntTest struct {
+				Ptr *big.Int `testfill:"42"`
+			}
+
+			result, err := testfill.Fill(BigIntTest{Ptr: big.NewInt(7)})
+			require.NoError(t, err)
+
+			require.Equal(t, int64(7), result.Ptr.Int64())
+		})
+
+		t.Run("invalid big.Int tag", func(t *testing.T) {
+			type InvalidBigInt struct {
+				Value big.Int `testfill:"not_a_number"`
+			}
+
+			_, err := testfill.Fill(InvalidBigInt{})
+
+			require.EqualError(t, err, "testfill: failed to set field Value: cannot convert \"not_a_number\" to big.Int")
+		})
+
+		t.Run("invalid big.Float tag", func(t *testing.T) {
+			type InvalidBigFloat struct {
+				Value *big.Float `testfill:"1.2.3"`
+			}
+
+			result, err := testfill.Fill(InvalidBigFloat{})
+
+			require.EqualError(t, err, "testfill: failed to set field Value: cannot convert \"1.2.3\" to big.Float")
+			require.Nil(t, result.Value)
+		})
+	})
+
+	t.Run("WithAutoFillEmbedded", func(t *testing.T) {
+		type Embedded struct {
+			EmbeddedField string `testfill:"embedded value"`
+		}
+		type ContainerStruct struct {
+			Embedded
+			*Bar
+			OtherField string `testfill:"other value"`
+		}
+
+		t.Run("fills embedded structs without fill tag", func(t *testing.T) {
+			result, err := testfill.FillWithOptions(ContainerStruct{}, testfill.WithAutoFillEmbedded(true))
+			require.NoError(t, err)
+
+			require.Equal(t, "embedded value", result.EmbeddedField)
+			require.NotNil(t, result.Bar)
+			require.Equal(t, Bar{Integer: 42, String: "Olivie Smith"}, *result.Bar)
+			require.Equal(t, "other value", result.OtherField)
+		})
+
+		t.Run("preserves existing embedded values", func(t *testing.T) {
+			input := ContainerStruct{Embedded: Embedded{EmbeddedField: "custom"}}
+			result, err := testfill.FillWithOptions(input, testfill.WithAutoFillEmbedded(true))
+			require.NoError(t, err)
+
+			require.Equal(t, "custom", result.EmbeddedField)
+		})
+
+		t.Run("leaves embedded structs empty when disabled", func(t *testing.T) {
+			result, err := testfill.FillWithOptions(ContainerStruct{}, testfill.WithAutoFillEmbedded(false))
+			require.NoError(t, err)
+
+			require.Equal(t, "", result.EmbeddedField)
+			require.Nil(t, result.Bar)
+			require.Equal(t, "other value", result.OtherField)
+		})
+	})
+
+	t.Run("fill with json overrides", func(t *testing.T) {
+		type Address struct {
+			Street string `json:"street" testfill:"123 Main"`
+			City   string `json:"city" testfill:"NYC"`
+		}
+
+		t.Run("merges json on top of filled defaults", func(t *testing.T) {
+			type Person struct {
+				Address Address `testfill:"fill" testfill_json:"{\"city\":\"Boston\"}"`
+			}
+
+			result, err := testfill.Fill(Person{})
+			require.NoError(t, err)
+
+			require.Equal(t, Address{Street: "123 Main", City: "Boston"}, result.Address)
+		})
+
+		t.Run("merges json into nested pointer", func(t *testing.T) {
+			type Person struct {
+				Address *Address `testfill:"fill" testfill_json:"{\"street\":\"5th Avenue\"}"`
+			}
+
+			result, err := testfill.Fill(Person{})
+			require.NoError(t, err)
+
+			require.NotNil(t, result.Address)
+			require.Equal(t, Address{Street: "5th Avenue", City: "NYC"}, *result.Address)
+		})
+
+		t.Run("keeps caller values of a pre-populated nested struct", func(t *testing.T) {
+			type Person struct {
+				Address  Address  `testfill:"fill" testfill_json:"{\"city\":\"Boston\",\"street\":\"5th\"}"`
+				Previous *Address `testfill:"fill" testfill_json:"{\"city\":\"Boston\"}"`
+			}
+
+			result, err := testfill.Fill(Person{
+				Address:  Address{City: "Rome"},
+				Previous: &Address{City: "Paris"},
+			})
+			require.NoError(t, err)
+
+			require.Equal(t, Address{Street: "5th", City: "Rome"}, result.Address)
+			require.Equal(t, Address{Street: "123 Main", City: "Paris"}, *result.Previous)
+		})
+
+		t.Run("invalid json", func(t *testing.T) {
+			type Person struct {
+				Address Address `testfill:"fill" testfill_json:"{invalid}"`
+			}
+
+			_, err := testfill.Fill(Person{})
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "testfill: failed to set field Address: failed to unmarshal JSON")
+		})
+	})
+
+	t.Run("FillJSON", func(t *testing.T) {
+		type Profile struct {
+			Name  string `json:"name" testfill:"John"`
+			Age   int    `json:"age" testfill:"30"`
+			Email string `json:"email" testfill:"john@example.com"`
+			Bar   Bar    `json:"bar" testfill:"fill"`
+		}
+
+		t.Run("fills gaps left by json", func(t *testing.T) {
+			result, err := testfill.FillJSON[Profile]([]byte(`{"name":"Alice","bar":{"Integer":7}}`))
+			require.NoError(t, err)
+
+			require.Equal(t, "Alice", result.Name)
+			require.Equal(t, 30, result.Age)
+			require.Equal(t, "john@example.com", result.Email)
+			require.Equal(t, Bar{Integer: 7, String: "Olivie Smith"}, result.Bar)
+		})
+
+		t.Run("invalid json", func(t *testing.T) {
+			result, err := testfill.FillJSON[Profile]([]byte(`{invalid}`))
+			require.Error(t, err)
+
+			require.Contains(t, err.Error(), "testfill: failed to unmarshal input JSON")
+			require.Equal(t, Profile{}, result)
+		})
+
+		t.Run("non-struct type", func(t *testing.T) {
+			_, err := testfill.FillJSON[[]int]([]byte(`[1,2]`))
+
+			require.EqualError(t, err, "testfill: expected struct, got []int")
+		})
+	})
+
+	t.Run("FieldError", func(t *testing.T) {
+		type Inner struct {
+			InvalidInt int `testfill:"not_a_number"`
+		}
+		type Middle struct {
+			Inner *Inner `testfill:"fill"`
+		}
+		type Outer struct {
+			Middle Middle `testfill:"fill"`
+		}
+
+		t.Run("exposes the failing field", func(t *testing.T) {
+			type Flat struct {
+				Value int `testfill:"not_a_number"`
+			}
+
+			_, err := testfill.Fill(Flat{})
+
+			var fieldErr *testfill.FieldError
+			require.True(t, errors.As(err, &fieldErr))
+			require.Empty(t, fieldErr.Path)
+			require.Equal(t, "Value", fieldErr.Field)
+			require.EqualError(t, fieldErr.Cause, "cannot convert \"not_a_number\" to int: strconv.ParseInt: parsing \"not_a_number\": invalid syntax")
+			require.EqualError(t, err, "testfill: failed to set field Value: cannot convert \"not_a_number\" to int: strconv.ParseInt: parsing \"not_a_number\": invalid syntax")
+		})
+
+		t.Run("exposes the path of nested fields", func(t *testing.T) {
+			_, err := testfill.Fill(Outer{})
+
+			var fieldErr *testfill.FieldError
+			require.True(t, errors.As(err, &fieldErr))
+			require.Equal(t, []string{"Middle", "Inner"}, fieldErr.Path)
+			require.Equal(t, "InvalidInt", fieldErr.Field)
+
+			expectedError := "testfill: failed to fill nested struct Middle: testfill: failed to fill nested struct pointer Inner: testfill: failed to set field Middle.Inner.InvalidInt: cannot convert \"not_a_number\" to int: strconv.ParseInt: parsing \"not_a_number\": invalid syntax"
+			require.EqualError(t, err, expectedError)
+		})
+
+		t.Run("exposes the path through slice elements", func(t *testing.T) {
+			type Container struct {
+				Items []Inner `testfill:"fill:2"`
+			}
+
+			_, err := testfill.Fill(Container{})
+
+			var fieldErr *testfill.FieldError
+			require.True(t, errors.As(err, &fieldErr))
+			require.Equal(t, []string{"Items", "[0]"}, fieldErr.Path)
+			require.Equal(t, "InvalidInt", fieldErr.Field)
+		})
+	})
+
+	t.Run("uintptr", func(t *testing.T) {
+		testfill.RegisterFactory("NewUintptrSum", func(a, b uintptr) uintptr {
+			return a + b
+		})
+
+		t.Run("fills fields, slices, maps and factory args", func(t *testing.T) {
+			type UintptrTest struct {
+				Value   uintptr             `testfill:"42"`
+				Ptr     *uintptr            `testfill:"7"`
+				Slice   []uintptr           `testfill:"1,2,3"`
+				Map     map[uintptr]uintptr `testfill:"1:10,2:20"`
+				Factory uintptr             `testfill:"factory:NewUintptrSum:3:4"`
+			}
+
+			result, err := testfill.Fill(UintptrTest{})
+			require.NoError(t, err)
+
+			require.Equal(t, uintptr(42), result.Value)
+			require.NotNil(t, result.Ptr)
+			require.Equal(t, uintptr(7), *result.Ptr)
+			require.Equal(t, []uintptr{1, 2, 3}, result.Slice)
+			require.Equal(t, map[uintptr]uintptr{1: 10, 2: 20}, result.Map)
+			require.Equal(t, uintptr(7), result.Factory)
+		})
+
+		t.Run("invalid uintptr tag", func(t *testing.T) {
+			type InvalidUintptr struct {
+				Value uintptr `testfill:"-1"`
+			}
+
+			_, err := testfill.Fill(InvalidUintptr{})
+
+			require.EqualError(t, err, "testfill: failed to set field Value: cannot convert \"-1\" to uintptr: strconv.ParseUint: parsing \"-1\": invalid syntax")
+		})
+	})
+
+	t.Run("RegisterZeroChecker", func(t *testing.T) {
+		type Money struct {
+			Currency string
+			Amount   int
+		}
+		type Order struct {
+			Total Money  `testfill:"factory:NewMoney"`
+			Note  string `testfill:"note"`
+		}
+
+		testfill.RegisterFactory("NewMoney", func() Money {
+			return Money{Currency: "EUR", Amount: 100}
+		})
+		testfill.RegisterZeroChecker(reflect.TypeOf(Money{}), func(v reflect.Value) bool {
+			return v.Interface().(Money).Amount == 0
+		})
+
+		t.Run("refills logically empty values", func(t *testing.T) {
+			result, err := testfill.Fill(Order{Total: Money{Currency: "USD"}})
+			require.NoError(t, err)
+
+			require.Equal(t, Money{Currency: "EUR", Amount: 100}, result.Total)
+		})
+
+		t.Run("preserves logically non-empty values", func(t *testing.T) {
+			result, err := testfill.Fill(Order{Total: Money{Currency: "USD", Amount: 5}})
+			require.NoError(t, err)
+
+			require.Equal(t, Money{Currency: "USD", Amount: 5}, result.Total)
+		})
+
+		t.Run("other types keep the default zero check", func(t *testing.T) {
+			result, err := testfill.Fill(Order{Note: "custom"})
+			require.NoError(t, err)
+
+			require.Equal(t, "custom", result.Note)
+		})
+	})
+
+	t.Run("fixed-length arrays", func(t *testing.T) {
+		type User struct {
+			Name string `testfill:"John" testfill_admin:"Jane" testfill_guest:"Bob"`
+		}
+
+		t.Run("array of primitives", func(t *testing.T) {
+			type ArrayTest struct {
+				Ints    [3]int    `testfill:"1,2,3"`
+				Strings [2]string `testfill:"a, b"`
+			}
+
+			result, err := testfill.Fill(ArrayTest{})
+			require.NoError(t, err)
+
+			require.Equal(t, [3]int{1, 2, 3}, result.Ints)
+			require.Equal(t, [2]string{"a", "b"}, result.Strings)
+		})
+
+		t.Run("array of structs with fill tag", func(t *testing.T) {
+			type ArrayTest struct {
+				Items [3]Bar `testfill:"fill"`
+			}
+
+			result, err := testfill.Fill(ArrayTest{Items: [3]Bar{{Integer: 1}}})
+			require.NoError(t, err)
+
+			require.Equal(t, [3]Bar{
+				{Integer: 1, String: "Olivie Smith"},
+				{Integer: 42, String: "Olivie Smith"},
+				{Integer: 42, String: "Olivie Smith"},
+			}, result.Items)
+		})
+
+		t.Run("array of structs with fill count", func(t *testing.T) {
+			type ArrayTest struct {
+				Items [2]Bar `testfill:"fill:2"`
+			}
+
+			result, err := testfill.Fill(ArrayTest{})
+			require.NoError(t, err)
+
+			require.Equal(t, [2]Bar{{Integer: 42, String: "Olivie Smith"}, {Integer: 42, String: "Olivie Smith"}}, result.Items)
+		})
+
+		t.Run("array of structs with variants", func(t *testing.T) {
+			type ArrayTest struct {
+				Users [3]User `testfill:"variants:admin,default,guest"`
+			}
+
+			result, err := testfill.Fill(ArrayTest{})
+			require.NoError(t, err)
+
+			require.Equal(t, [3]User{{Name: "Jane"}, {Name: "John"}, {Name: "Bob"}}, result.Users)
+		})
+
+		t.Run("does not fill when value is already filled", func(t *testing.T) {
+			type ArrayTest struct {
+				Ints [2]int `testfill:"1,2"`
+			}
+
+			result, err := testfill.Fill(ArrayTest{Ints: [2]int{0, 9}})
+			require.NoError(t, err)
+
+			require.Equal(t, [2]int{0, 9}, result.Ints)
+		})
+
+		t.Run("variant count mismatch", func(t *testing.T) {
+			type ArrayTest struct {
+				Users [3]User `testfill:"variants:admin,guest"`
+			}
+
+			result, err := testfill.Fill(ArrayTest{})
+
+			require.EqualError(t, err, "testfill: failed to set field Users: array of length 3 cannot be filled with 2 values")
+			require.Equal(t, ArrayTest{}, result)
+		})
+
+		t.Run("value count mismatch", func(t *testing.T) {
+			type ArrayTest struct {
+				Ints [2]int `testfill:"1,2,3"`
+			}
+
+			_, err := testfill.Fill(ArrayTest{})
+
+			require.EqualError(t, err, "testfill: failed to set field Ints: array of length 2 cannot be filled with 3 values")
+		})
+
+		t.Run("array element fill error", func(t *testing.T) {
+			type StructWithError struct {
+				InvalidField int `testfill:"not_a_number"`
+			}
+			type ArrayTest struct {
+				Items [2]StructWithError `testfill:"fill"`
+			}
+
+			_, err := testfill.Fill(ArrayTest{})
+
+			expectedError := "testfill: failed to fill nested array Items element 0: testfill: failed to set field Items[0].InvalidField: cannot convert \"not_a_number\" to int: strconv.ParseInt: parsing \"not_a_number\": invalid syntax"
+			require.EqualError(t, err, expectedError)
+		})
+	})
+
+	t.Run("WithDisallowUnknownFields", func(t *testing.T) {
+		type Person struct {
+			Name string `json:"name" testfill:"John"`
+			Age  int    `json:"age" testfill:"30"`
+		}
+
+		t.Run("unmarshal tag rejects unknown fields", func(t *testing.T) {
+			type Container struct {
+				Person Person `testfill:"unmarshal:{\"name\":\"Alice\",\"agee\":30}"`
+			}
+
+			_, err := testfill.FillWithOptions(Container{}, testfill.WithDisallowUnknownFields(true))
+
+			require.EqualError(t, err, "testfill: failed to set field Person: failed to unmarshal JSON: json: unknown field \"agee\"")
+		})
+
+		t.Run("unmarshal tag is lenient by default", func(t *testing.T) {
+			type Container struct {
+				Person Person `testfill:"unmarshal:{\"name\":\"Alice\",\"agee\":30}"`
+			}
+
+			result, err := testfill.FillWithOptions(Container{})
+			require.NoError(t, err)
+
+			require.Equal(t, Person{Name: "Alice"}, result.Person)
+		})
+
+		t.Run("testfill_json tag rejects unknown fields", func(t *testing.T) {
+			type Container struct {
+				Person Person `testfill:"fill" testfill_json:"{\"nme\":\"Alice\"}"`
+			}
+
+			_, err := testfill.FillWithOptions(Container{}, testfill.WithDisallowUnknownFields(true))
+
+			require.EqualError(t, err, "testfill: failed to set field Person: failed to unmarshal JSON: json: unknown field \"nme\"")
+		})
+
+		t.Run("FillJSON rejects unknown fields", func(t *testing.T) {
+			_, err := testfill.FillJSON[Person]([]byte(`{"nme":"Alice"}`), testfill.WithDisallowUnknownFields(true))
+
+			require.EqualError(t, err, "testfill: failed to unmarshal input JSON: json: unknown field \"nme\"")
+		})
+
+		t.Run("rejects trailing data", func(t *testing.T) {
+			_, err := testfill.FillJSON[Person]([]byte(`{"name":"Alice"}}`))
+
+			require.EqualError(t, err, "testfill: failed to unmarshal input JSON: invalid data after top-level JSON value")
+		})
+	})
+
+	t.Run("MustFillJSON", func(t *testing.T) {
+		type Person struct {
+			Name string `json:"name" testfill:"John"`
+			Age  int    `json:"age" testfill:"30"`
+		}
+
+		t.Run("fills gaps left by json", func(t *testing.T) {
+			result := testfill.MustFillJSON[Person]([]byte(`{"name":"Alice"}`))
+
+			require.Equal(t, Person{Name: "Alice", Age: 30}, result)
+		})
+
+		t.Run("panics on error", func(t *testing.T) {
+			require.Panics(t, func() {
+				testfill.MustFillJSON[Person]([]byte(`{"nme":"Alice"}`), testfill.WithDisallowUnknownFields(true))
+			})
+		})
+	})
+
+	t.Run("human-readable numbers", func(t *testing.T) {
+		t.Run("digit separators", func(t *testing.T) {
+			type NumberTest struct {
+				Int   int     `testfill:"1_000_000"`
+				Uint  uint32  `testfill:"65_535"`
+				Float float64 `testfill:"1_000.5"`
+			}
+
+			result, err := testfill.Fill(NumberTest{})
+			require.NoError(t, err)
+
+			require.Equal(t, 1000000, result.Int)
+			require.Equal(t, uint32(65535), result.Uint)
+			require.Equal(t, 1000.5, result.Float)
+		})
+
+		t.Run("k, M and G suffixes", func(t *testing.T) {
+			type NumberTest struct {
+				Kilo  int         `testfill:"10k"`
+				Mega  int64       `testfill:"-2M"`
+				Giga  uint64      `testfill:"3G"`
+				Float float64     `testfill:"1.5k"`
+				Slice []int       `testfill:"1k,2k"`
+				Map   map[int]int `testfill:"1k:2M"`
+			}
+
+			result, err := testfill.Fill(NumberTest{})
+			require.NoError(t, err)
+
+			require.Equal(t, 10000, result.Kilo)
+			require.Equal(t, int64(-2000000), result.Mega)
+			require.Equal(t, uint64(3000000000), result.Giga)
+			require.Equal(t, 1500.0, result.Float)
+			require.Equal(t, []int{1000, 2000}, result.Slice)
+			require.Equal(t, map[int]int{1000: 2000000}, result.Map)
+		})
+
+		t.Run("invalid suffix combination", func(t *testing.T) {
+			type NumberTest struct {
+				Value int `testfill:"10kM"`
+			}
+
+			_, err := testfill.Fill(NumberTest{})
+
+			require.EqualError(t, err, "testfill: failed to set field Value: cannot convert \"10kM\" to int: strconv.ParseInt: parsing \"10kM\": invalid syntax")
+		})
+
+		t.Run("fractional integer with suffix", func(t *testing.T) {
+			type NumberTest struct {
+				Value int `testfill:"1.5k"`
+			}
+
+			_, err := testfill.Fill(NumberTest{})
+
+			require.EqualError(t, err, "testfill: failed to set field Value: cannot convert \"1.5k\" to int: strconv.ParseInt: parsing \"1.5k\": invalid syntax")
+		})
+
+		t.Run("suffix overflowing the field size", func(t *testing.T) {
+			type NumberTest struct {
+				Value int8 `testfill:"1k"`
+			}
+
+			_, err := testfill.Fill(NumberTest{})
+
+			require.EqualError(t, err, "testfill: failed to set field Value: cannot convert \"1k\" to int8: strconv.ParseInt: parsing \"1k\": value out of range")
+		})
+
+		t.Run("unsigned suffix overflowing the field size", func(t *testing.T) {
+			type NumberTest struct {
+				Value uint16 `testfill:"1M"`
+			}
+
+			_, err := testfill.Fill(NumberTest{})
+
+			require.EqualError(t, err, "testfill: failed to set field Value: cannot convert \"1M\" to uint16: strconv.ParseUint: parsing \"1M\": value out of range")
+		})
+
+		t.Run("float suffix overflowing the field size", func(t *testing.T) {
+			type NumberTest struct {
+				Value float32 `testfill:"1e38k"`
+			}
+
+			_, err := testfill.Fill(NumberTest{})
+
+			require.EqualError(t, err, "testfill: failed to set field Value: cannot convert \"1e38k\" to float32: strconv.ParseFloat: parsing \"1e38k\": value out of range")
+		})
+	})
+
+	t.Run("top-level collections", func(t *testing.T) {
+		t.Run("fills every slice element", func(t *testing.T) {
+			input := []Bar{{}, {Integer: 7}}
+
+			result, err := testfill.Fill(input)
+			require.NoError(t, err)
+
+			require.Equal(t, []Bar{{Integer: 42, String: "Olivie Smith"}, {Integer: 7, String: "Olivie Smith"}}, result)
+			require.Equal(t, []Bar{{}, {Integer: 7}}, input)
+		})
+
+		t.Run("fills every map value", func(t *testing.T) {
+			input := map[string]Bar{"a": {}, "b": {String: "custom"}}
+
+			result, err := testfill.Fill(input)
+			require.NoError(t, err)
+
+			require.Equal(t, map[string]Bar{
+				"a": {Integer: 42, String: "Olivie Smith"},
+				"b": {Integer: 42, String: "custom"},
+			}, result)
+			require.Equal(t, map[string]Bar{"a": {}, "b": {String: "custom"}}, input)
+		})
+
+		t.Run("fills slice elements with variant", func(t *testing.T) {
+			type User struct {
+				Name string `testfill:"John" testfill_admin:"Jane"`
+			}
+
+			result, err := testfill.FillWithVariant([]User{{}, {}}, "admin")
+			require.NoError(t, err)
+
+			require.Equal(t, []User{{Name: "Jane"}, {Name: "Jane"}}, result)
+		})
+
+		t.Run("keeps nil collections nil", func(t *testing.T) {
+			slice, err := testfill.Fill([]Bar(nil))
+			require.NoError(t, err)
+			require.Nil(t, slice)
+
+			m, err := testfill.Fill(map[string]Bar(nil))
+			require.NoError(t, err)
+			require.Nil(t, m)
+		})
+
+		t.Run("slice element error", func(t *testing.T) {
+			type StructWithError struct {
+				InvalidField int `testfill:"not_a_number"`
+			}
+
+			result, err := testfill.Fill([]StructWithError{{}})
+
+			expectedError := "testfill: failed to fill element 0: testfill: failed to set field [0].InvalidField: cannot convert \"not_a_number\" to int: strconv.ParseInt: parsing \"not_a_number\": invalid syntax"
+			require.EqualError(t, err, expectedError)
+			require.Nil(t, result)
+		})
+
+		t.Run("map value error", func(t *testing.T) {
+			type StructWithError struct {
+				InvalidField int `testfill:"not_a_number"`
+			}
+
+			_, err := testfill.Fill(map[string]StructWithError{"key": {}})
+
+			expectedError := "testfill: failed to fill map value for key key: testfill: failed to set field [key].InvalidField: cannot convert \"not_a_number\" to int: strconv.ParseInt: parsing \"not_a_number\": invalid syntax"
+			require.EqualError(t, err, expectedError)
+		})
+
+		t.Run("map of non-struct values returns error", func(t *testing.T) {
+			_, err := testfill.Fill(map[string]int{"a": 1})
+
+			require.EqualError(t, err, "testfill: expected struct, got map[string]int")
+		})
+	})
+
+	t.Run("Plan", func(t *testing.T) {
+		type Item struct {
+			Name string `testfill:"item"`
+		}
+		type Order struct {
+			ID      int    `testfill:"7"`
+			Note    string `testfill:"note"`
+			Comment string
+			Count   *int   `testfill:"3"`
+			Bar     Bar    `testfill:"fill"`
+			Items   []Item `testfill:"fill:2"`
+		}
+
+		t.Run("reports filled and skipped fields", func(t *testing.T) {
+			input := Order{Note: "custom"}
+
+			actions, err := testfill.Plan(input)
+			require.NoError(t, err)
+
+			require.Equal(t, []testfill.FillAction{
+				{Path: "ID", Tag: "7", Value: "7"},
+				{Path: "Note", Tag: "note", Value: "custom", Skipped: testfill.SkipNonZero},
+				{Path: "Comment", Tag: "", Value: "", Skipped: testfill.SkipNoTag},
+				{Path: "Count", Tag: "3", Value: "3"},
+				{Path: "Bar.Integer", Tag: "42", Value: "42"},
+				{Path: "Bar.String", Tag: "Olivie Smith", Value: "Olivie Smith"},
+				{Path: "Items[0].Name", Tag: "item", Value: "item"},
+				{Path: "Items[1].Name", Tag: "item", Value: "item"},
+				{Path: "Items", Tag: "fill:2", Value: "[{item} {item}]"},
+			}, actions)
+			require.Equal(t, Order{Note: "custom"}, input)
+		})
+
+		t.Run("leaves the caller's data untouched", func(t *testing.T) {
+			type Shipment struct {
+				Order *Order `testfill:"fill"`
+				Bars  []*Bar `testfill:"fill:1"`
+			}
+			order := &Order{Note: "custom"}
+			shipment := Shipment{Order: &Order{}, Bars: []*Bar{{}}}
+
+			actions, err := testfill.Plan(order)
+			require.NoError(t, err)
+			require.Contains(t, actions, testfill.FillAction{Path: "ID", Tag: "7", Value: "7"})
+			require.Equal(t, &Order{Note: "custom"}, order)
+
+			_, err = testfill.Plan(shipment)
+			require.NoError(t, err)
+			require.Equal(t, &Order{}, shipment.Order)
+			require.Equal(t, &Bar{}, shipment.Bars[0])
+		})
+
+		t.Run("returns fill errors", func(t *testing.T) {
+			type Invalid struct {
+				Value int `testfill:"not_a_number"`
+			}
+
+			actions, err := testfill.Plan(Invalid{})
+
+			require.EqualError(t, err, "testfill: failed to set field Value: cannot convert \"not_a_number\" to int: strconv.ParseInt: parsing \"not_a_number\": invalid syntax")
+			require.Nil(t, actions)
+		})
+
+		t.Run("does not advance the incr counters", func(t *testing.T) {
+			type Inc struct {
+				ID int `testfill:"incr"`
+			}
+			testfill.ResetCounters()
+			defer testfill.ResetCounters()
+
+			for i := 0; i < 2; i++ {
+				actions, err := testfill.Plan(Inc{})
+				require.NoError(t, err)
+				require.Equal(t, []testfill.FillAction{{Path: "ID", Tag: "incr", Value: "1"}}, actions)
+			}
+
+			result, err := testfill.Fill(Inc{})
+			require.NoError(t, err)
+			require.Equal(t, 1, result.ID)
+		})
+	})
+
+	t.Run("WithSkipEmptyPointers", func(t *testing.T) {
+		type Untagged struct {
+			Name string
+		}
+		type Container struct {
+			Tagged   *Bar      `testfill:"fill"`
+			Untagged *Untagged `testfill:"fill"`
+		}
+
+		t.Run("leaves pointers to untagged structs nil", func(t *testing.T) {
+			result, err := testfill.FillWithOptions(Container{}, testfill.WithSkipEmptyPointers(true))
+			require.NoError(t, err)
+
+			require.NotNil(t, result.Tagged)
+			require.Equal(t, Bar{Integer: 42, String: "Olivie Smith"}, *result.Tagged)
+			require.Nil(t, result.Untagged)
+		})
+
+		t.Run("keeps existing pointers", func(t *testing.T) {
+			existing := &Untagged{Name: "custom"}
+			result, err := testfill.FillWithOptions(Container{Untagged: existing}, testfill.WithSkipEmptyPointers(true))
+			require.NoError(t, err)
+
+			require.Equal(t, existing, result.Untagged)
+		})
+
+		t.Run("allocates pointers by default", func(t *testing.T) {
+			result, err := testfill.Fill(Container{})
+			require.NoError(t, err)
+
+			require.NotNil(t, result.Untagged)
+			require.Equal(t, Untagged{}, *result.Untagged)
+		})
+	})
+
+	t.Run("error field path", func(t *testing.T) {
+		type Baz struct {
+			Value int `testfill:"not_a_number"`
+		}
+		type Bar struct {
+			Items []Baz `testfill:"fill:3"`
+		}
+		type Foo struct {
+			Bar Bar `testfill:"fill"`
+		}
+
+		t.Run("reports the dotted path of the failing field", func(t *testing.T) {
+			_, err := testfill.Fill(Foo{})
+
+			require.ErrorContains(t, err, "testfill: failed to set field Bar.Items[0].Value: cannot convert")
+
+			var fieldErr *testfill.FieldError
+			require.True(t, errors.As(err, &fieldErr))
+			require.Equal(t, []string{"Bar", "Items", "[0]"}, fieldErr.Path)
+			require.Equal(t, "Value", fieldErr.Field)
+		})
+
+		t.Run("reports map keys in the path", func(t *testing.T) {
+			type Container struct {
+				Values map[string]Baz `testfill:"first:fill"`
+			}
+
+			_, err := testfill.Fill(Container{})
+
+			require.ErrorContains(t, err, "testfill: failed to set field Values[first].Value: cannot convert")
+		})
+	})
+
+	t.Run("nested containers", func(t *testing.T) {
+		t.Run("slice of maps", func(t *testing.T) {
+			type NestedTest struct {
+				Value []map[string]int `testfill:"a=1;b=2,c=3"`
+			}
+
+			result, err := testfill.Fill(NestedTest{})
+			require.NoError(t, err)
+
+			require.Equal(t, []map[string]int{{"a": 1, "b": 2}, {"c": 3}}, result.Value)
+		})
+
+		t.Run("map of maps", func(t *testing.T) {
+			type NestedTest struct {
+				Value map[string]map[string]int `testfill:"x:a=1;b=2,y:c=3"`
+			}
+
+			result, err := testfill.Fill(NestedTest{})
+			require.NoError(t, err)
+
+			require.Equal(t, map[string]map[string]int{"x": {"a": 1, "b": 2}, "y": {"c": 3}}, result.Value)
+		})
+
+		t.Run("slice of slices and map of slices", func(t *testing.T) {
+			type NestedTest struct {
+				Matrix [][]int             `testfill:"1;2,3;4"`
+				Groups map[string][]string `testfill:"x:a;b,y:c"`
+			}
+
+			result, err := testfill.Fill(NestedTest{})
+			require.NoError(t, err)
+
+			require.Equal(t, [][]int{{1, 2}, {3, 4}}, result.Matrix)
+			require.Equal(t, map[string][]string{"x": {"a", "b"}, "y": {"c"}}, result.Groups)
+		})
+
+		t.Run("invalid nested value", func(t *testing.T) {
+			type NestedTest struct {
+				Value []map[string]int `testfill:"a=1;b=x"`
+			}
+
+			_, err := testfill.Fill(NestedTest{})
+
+			require.EqualError(t, err, "testfill: failed to set field Value: unsupported map type string -> int")
+		})
+
+		t.Run("invalid nested map format", func(t *testing.T) {
+			type NestedTest struct {
+				Value map[string]map[string]int `testfill:"x:a;b"`
+			}
+
+			_, err := testfill.Fill(NestedTest{})
+
+			require.EqualError(t, err, "testfill: failed to set field Value: invalid map format: a")
+		})
+
+		t.Run("too deeply nested", func(t *testing.T) {
+			type NestedTest struct {
+				Value [][][]int `testfill:"1"`
+			}
+
+			_, err := testfill.Fill(NestedTest{})
+
+			require.EqualError(t, err, "testfill: failed to set field Value: unsupported container type []int: containers can be nested at most 2 levels deep")
+		})
+	})
+
+	t.Run("field references", func(t *testing.T) {
+		t.Run("interpolates sibling field values", func(t *testing.T) {
+			type User struct {
+				Email string `testfill:"${Name}@example.com"`
+				Name  string `testfill:"john"`
+				Label string `testfill:"${Name} (${Age})"`
+				Age   int    `testfill:"30"`
+			}
+
+			result, err := testfill.Fill(User{})
+			require.NoError(t, err)
+
+			require.Equal(t, "john@example.com", result.Email)
+			require.Equal(t, "john (30)", result.Label)
+		})
+
+		t.Run("uses caller provided values", func(t *testing.T) {
+			type User struct {
+				Name  string `testfill:"john"`
+				Email string `testfill:"${Name}@example.com"`
+			}
+
+			result, err := testfill.Fill(User{Name: "alice"})
+			require.NoError(t, err)
+
+			require.Equal(t, "alice@example.com", result.Email)
+		})
+
+		t.Run("resolves chained references", func(t *testing.T) {
+			type User struct {
+				URL    string `testfill:"https://${Domain}/home"`
+				Domain string `testfill:"${Name}.example.com"`
+				Name   string `testfill:"john"`
+			}
+
+			result, err := testfill.Fill(User{})
+			require.NoError(t, err)
+
+			require.Equal(t, "https://john.example.com/home", result.URL)
+		})
+
+		t.Run("leaves directive bodies alone", func(t *testing.T) {
+			type Job struct {
+				Env  map[string]string `testfill:"unmarshal:{\"cmd\":\"echo ${HOME}\"}"`
+				Name string            `testfill:"build"`
+			}
+
+			result, err := testfill.Fill(Job{})
+			require.NoError(t, err)
+
+			require.Equal(t, map[string]string{"cmd": "echo ${HOME}"}, result.Env)
+		})
+
+		t.Run("converts interpolated values", func(t *testing.T) {
+			type Limits struct {
+				Base int `testfill:"5"`
+				Max  int `testfill:"${Base}00"`
+			}
+
+			result, err := testfill.Fill(Limits{})
+			require.NoError(t, err)
+
+			require.Equal(t, 500, result.Max)
+		})
+
+		t.Run("does not fill when value is already filled", func(t *testing.T) {
+			type User struct {
+				Email string `testfill:"${Missing}@example.com"`
+			}
+
+			result, err := testfill.Fill(User{Email: "custom@example.com"})
+			require.NoError(t, err)
+
+			require.Equal(t, "custom@example.com", result.Email)
+		})
+
+		t.Run("unknown field", func(t *testing.T) {
+			type User struct {
+				Email string `testfill:"${Missing}@example.com"`
+			}
+
+			_, err := testfill.Fill(User{})
+
+			require.EqualError(t, err, "testfill: failed to set field Email: unknown field Missing referenced")
+		})
+
+		t.Run("field promoted through a nil embedded pointer", func(t *testing.T) {
+			type Profile struct {
+				Name string
+			}
+			type User struct {
+				*Profile
+				Email string `testfill:"${Name}@example.com"`
+			}
+
+			_, err := testfill.Fill(User{})
+			require.EqualError(t, err, "testfill: failed to set field Email: field Name is promoted through a nil embedded pointer")
+
+			result, err := testfill.Fill(User{Profile: &Profile{Name: "jane"}})
+			require.NoError(t, err)
+			require.Equal(t, "jane@example.com", result.Email)
+		})
+
+		t.Run("circular reference", func(t *testing.T) {
+			type User struct {
+				A string `testfill:"${B}"`
+				B string `testfill:"${A}"`
+			}
+
+			_, err := testfill.Fill(User{})
+
+			require.EqualError(t, err, "testfill: failed to set field A: circular reference to field A")
+		})
+	})
+
+	t.Run("sequences", func(t *testing.T) {
+		type Row struct {
+			ID    int    `testfill:"seq"`
+			Code  uint16 `testfill:"seq:100"`
+			Label string `testfill:"row-${ID}"`
+		}
+
+		t.Run("yields the element index in struct slices", func(t *testing.T) {
+			type Table struct {
+				Rows []Row `testfill:"fill:3"`
+			}
+
+			result, err := testfill.Fill(Table{})
+			require.NoError(t, err)
+
+			require.Equal(t, []Row{
+				{ID: 0, Code: 100, Label: "row-0"},
+				{ID: 1, Code: 101, Label: "row-1"},
+				{ID: 2, Code: 102, Label: "row-2"},
+			}, result.Rows)
+		})
+
+		t.Run("yields the element index with variants and arrays", func(t *testing.T) {
+			type Table struct {
+				Rows  []Row  `testfill:"variants:a,b"`
+				Fixed [2]Row `testfill:"fill"`
+			}
+
+			result, err := testfill.Fill(Table{})
+			require.NoError(t, err)
+
+			require.Equal(t, 1, result.Rows[1].ID)
+			require.Equal(t, uint16(101), result.Fixed[1].Code)
+		})
+
+		t.Run("yields the start value outside slices", func(t *testing.T) {
+			result, err := testfill.Fill(Row{})
+			require.NoError(t, err)
+
+			require.Equal(t, Row{ID: 0, Code: 100, Label: "row-0"}, result)
+		})
+
+		t.Run("invalid start value", func(t *testing.T) {
+			type Invalid struct {
+				ID int `testfill:"seq:abc"`
+			}
+
+			_, err := testfill.Fill(Invalid{})
+
+			require.EqualError(t, err, "testfill: failed to set field ID: invalid sequence seq:abc: strconv.ParseInt: parsing \"abc\": invalid syntax")
+		})
+	})
+
+	t.Run("unique random values", func(t *testing.T) {
+		type Record struct {
+			ID    string  `testfill:"rand:unique"`
+			Code  int16   `testfill:"rand:unique"`
+			Score float64 `testfill:"rand:unique"`
+		}
+
+		t.Run("generates distinct values across slice elements", func(t *testing.T) {
+			type Batch struct {
+				Records []Record `testfill:"fill:50"`
+			}
+
+			result, err := testfill.Fill(Batch{})
+			require.NoError(t, err)
+
+			ids := make(map[string]bool)
+			codes := make(map[int16]bool)
+			for _, record := range result.Records {
+				require.Len(t, record.ID, 16)
+				require.GreaterOrEqual(t, record.Code, int16(0))
+				ids[record.ID] = true
+				codes[record.Code] = true
+			}
+			require.Len(t, ids, 50)
+			require.Len(t, codes, 50)
+		})
+
+		t.Run("does not fill when value is already filled", func(t *testing.T) {
+			result, err := testfill.Fill(Record{ID: "custom"})
+			require.NoError(t, err)
+
+			require.Equal(t, "custom", result.ID)
+		})
+
+		t.Run("errors when unique values are exhausted", func(t *testing.T) {
+			type Tiny struct {
+				Value uint8 `testfill:"rand:unique"`
+			}
+			type Batch struct {
+				Values []Tiny `testfill:"fill:257"`
+			}
+
+			_, err := testfill.Fill(Batch{})
+
+			require.ErrorContains(t, err, "could not generate a unique uint8 value after 100 attempts")
+		})
+
+		t.Run("unknown rand mode", func(t *testing.T) {
+			type Invalid struct {
+				Value string `testfill:"rand:other"`
+			}
+
+			_, err := testfill.Fill(Invalid{})
+
+			require.EqualError(t, err, "testfill: failed to set field Value: unknown rand mode \"other\"")
+		})
+
+		t.Run("unsupported type", func(t *testing.T) {
+			type Invalid struct {
+				Value bool `testfill:"rand:unique"`
+			}
+
+			_, err := testfill.Fill(Invalid{})
+
+			require.EqualError(t, err, "testfill: failed to set field Value: unsupported type bool for random values")
+		})
+	})
+
+	t.Run("FillWithReport", func(t *testing.T) {
+		type Item struct {
+			Name string `testfill:"item"`
+		}
+		type Order struct {
+			ID      int    `testfill:"7"`
+			Note    string `testfill:"note"`
+			Comment string
+			Bar     Bar    `testfill:"fill"`
+			Items   []Item `testfill:"fill:2"`
+		}
+
+		t.Run("reports filled and skipped fields", func(t *testing.T) {
+			result, report, err := testfill.FillWithReport(Order{Note: "custom"})
+			require.NoError(t, err)
+
+			require.Equal(t, 7, result.ID)
+			require.Equal(t, []string{"ID", "Bar.Integer", "Bar.String", "Items[0].Name", "Items[1].Name", "Items"}, report.Filled)
+			require.Equal(t, []string{"Note"}, report.SkippedNonZero)
+			require.Equal(t, []string{"Comment"}, report.SkippedNoTag)
+		})
+
+		t.Run("returns fill errors", func(t *testing.T) {
+			type Invalid struct {
+				Value int `testfill:"not_a_number"`
+			}
+
+			_, report, err := testfill.FillWithReport(Invalid{})
+
+			require.Error(t, err)
+			require.Equal(t, testfill.Report{}, report)
+		})
+	})
+
+	t.Run("sync types", func(t *testing.T) {
+		type Counter struct {
+			sync.Mutex
+			Name  string       `testfill:"counter"`
+			Once  sync.Once    `testfill:"fill"`
+			Lock  sync.RWMutex `testfill:"locked"`
+			Total atomic.Int64 `testfill:"42"`
+		}
+
+		t.Run("fills structs embedding sync.Mutex", func(t *testing.T) {
+			result, err := testfill.Fill(Counter{})
+			require.NoError(t, err)
+
+			require.Equal(t, "counter", result.Name)
+			require.Equal(t, int64(0), result.Total.Load())
+			require.True(t, result.TryLock())
+		})
+
+		t.Run("fills nested lock-containing structs", func(t *testing.T) {
+			type Registry struct {
+				Counter *Counter `testfill:"fill"`
+			}
+
+			result, err := testfill.FillWithOptions(Registry{}, testfill.WithAutoFillEmbedded(true))
+			require.NoError(t, err)
+
+			require.NotNil(t, result.Counter)
+			require.Equal(t, "counter", result.Counter.Name)
+		})
+	})
+
+	t.Run("web types", func(t *testing.T) {
+		t.Run("url.URL fills default value", func(t *testing.T) {
+			type WebTest struct {
+				URL    url.URL  `testfill:"https://example.com/path?q=1"`
+				URLPtr *url.URL `testfill:"http://localhost:8080"`
+			}
+
+			result, err := testfill.Fill(WebTest{})
+			require.NoError(t, err)
+
+			require.Equal(t, "https", result.URL.Scheme)
+			require.Equal(t, "example.com", result.URL.Host)
+			require.Equal(t, "/path", result.URL.Path)
+			require.Equal(t, "q=1", result.URL.RawQuery)
+			require.NotNil(t, result.URLPtr)
+			require.Equal(t, "localhost:8080", result.URLPtr.Host)
+		})
+
+		t.Run("net.IP fills default value", func(t *testing.T) {
+			type WebTest struct {
+				IP    net.IP  `testfill:"10.0.0.1"`
+				IPPtr *net.IP `testfill:"::1"`
+			}
+
+			result, err := testfill.Fill(WebTest{})
+			require.NoError(t, err)
+
+			require.Equal(t, net.ParseIP("10.0.0.1"), result.IP)
+			require.NotNil(t, result.IPPtr)
+			require.Equal(t, net.ParseIP("::1"), *result.IPPtr)
+		})
+
+		t.Run("does not fill when value is already filled", func(t *testing.T) {
+			type WebTest struct {
+				IP net.IP `testfill:"10.0.0.1"`
+			}
+
+			result, err := testfill.Fill(WebTest{IP: net.ParseIP("192.168.0.1")})
+			require.NoError(t, err)
+
+			require.Equal(t, net.ParseIP("192.168.0.1"), result.IP)
+		})
+
+		t.Run("invalid url", func(t *testing.T) {
+			type WebTest struct {
+				URL url.URL `testfill:"http://[::1"`
+			}
+
+			_, err := testfill.Fill(WebTest{})
+
+			require.EqualError(t, err, "testfill: failed to set field URL: cannot convert \"http://[::1\" to url.URL: parse \"http://[::1\": missing ']' in host")
+		})
+
+		t.Run("invalid ip", func(t *testing.T) {
+			type WebTest struct {
+				IP *net.IP `testfill:"not-an-ip"`
+			}
+
+			result, err := testfill.Fill(WebTest{})
+
+			require.EqualError(t, err, "testfill: failed to set field IP: cannot convert \"not-an-ip\" to net.IP: invalid IP address: not-an-ip")
+			require.Nil(t, result.IP)
+		})
+	})
+
+	t.Run("repeated slice values", func(t *testing.T) {
+		t.Run("repeats a literal value", func(t *testing.T) {
+			type RepeatTest struct {
+				Ints    []int            `testfill:"repeat:3:7"`
+				Strings []string         `testfill:"repeat:2:hi"`
+				Times   []string         `testfill:"repeat:2:10:30"`
+				Empty   []int            `testfill:"repeat:0:1"`
+				Maps    []map[string]int `testfill:"repeat:2:a=1"`
+				Array   [2]float64       `testfill:"repeat:2:1.5"`
+			}
+
+			result, err := testfill.Fill(RepeatTest{})
+			require.NoError(t, err)
+
+			require.Equal(t, []int{7, 7, 7}, result.Ints)
+			require.Equal(t, []string{"hi", "hi"}, result.Strings)
+			require.Equal(t, []string{"10:30", "10:30"}, result.Times)
+			require.Equal(t, []int{}, result.Empty)
+			require.Equal(t, []map[string]int{{"a": 1}, {"a": 1}}, result.Maps)
+			require.Equal(t, [2]float64{1.5, 1.5}, result.Array)
+		})
+
+		t.Run("invalid count", func(t *testing.T) {
+			type RepeatTest struct {
+				Ints []int `testfill:"repeat:x:7"`
+			}
+
+			_, err := testfill.Fill(RepeatTest{})
+
+			require.EqualError(t, err, "testfill: failed to set field Ints: invalid repeat format: repeat:x:7 (expected format: repeat:count:value)")
+		})
+
+		t.Run("missing value", func(t *testing.T) {
+			type RepeatTest struct {
+				Ints []int `testfill:"repeat:3"`
+			}
+
+			_, err := testfill.Fill(RepeatTest{})
+
+			require.EqualError(t, err, "testfill: failed to set field Ints: invalid repeat format: repeat:3 (expected format: repeat:count:value)")
+		})
+
+		t.Run("invalid value", func(t *testing.T) {
+			type RepeatTest struct {
+				Ints []int `testfill:"repeat:3:x"`
+			}
+
+			_, err := testfill.Fill(RepeatTest{})
+
+			require.EqualError(t, err, "testfill: failed to set field Ints: unsupported slice element type int")
+		})
+	})
+
+	t.Run("strict tags", func(t *testing.T) {
+		t.Run("rejects misspelled directives", func(t *testing.T) {
+			type StrictTest struct {
+				Name string `testfill:"factroy:New"`
+			}
+
+			_, err := testfill.FillWithOptions(StrictTest{}, testfill.WithStrictTags(true))
+
+			require.EqualError(t, err, `testfill: failed to set field Name: unknown tag directive "factroy" (did you mean "factory"?)`)
+		})
+
+		t.Run("rejects unknown directives that are not literal values", func(t *testing.T) {
+			type StrictTest struct {
+				Count int `testfill:"env:COUNT"`
+			}
+
+			_, err := testfill.FillWithOptions(StrictTest{}, testfill.WithStrictTags(true))
+
+			require.EqualError(t, err, `testfill: failed to set field Count: unknown tag directive "env"`)
+		})
+
+		t.Run("accepts literal values containing colons", func(t *testing.T) {
+			type StrictTest struct {
+				URL     string         `testfill:"https://example.com"`
+				Clock   string         `testfill:"10:30"`
+				Scores  map[string]int `testfill:"key:1,other:2"`
+				Numbers []int          `testfill:"repeat:2:7"`
+			}
+
+			result, err := testfill.FillWithOptions(StrictTest{}, testfill.WithStrictTags(true))
+			require.NoError(t, err)
+
+			require.Equal(t, "https://example.com", result.URL)
+			require.Equal(t, "10:30", result.Clock)
+			require.Equal(t, map[string]int{"key": 1, "other": 2}, result.Scores)
+			require.Equal(t, []int{7, 7}, result.Numbers)
+		})
+
+		t.Run("accepts map tags whose keys look like directives", func(t *testing.T) {
+			type StrictTest struct {
+				Paging  map[string]int  `testfill:"page:1,size:20"`
+				Rate    map[string]int  `testfill:"rate:5"`
+				Short   map[string]int  `testfill:"t:5"`
+				Pointer *map[string]int `testfill:"rate:5"`
+			}
+
+			result, err := testfill.FillWithOptions(StrictTest{}, testfill.WithStrictTags(true))
+			require.NoError(t, err)
+
+			require.Equal(t, map[string]int{"page": 1, "size": 20}, result.Paging)
+			require.Equal(t, map[string]int{"rate": 5}, result.Rate)
+			require.Equal(t, map[string]int{"t": 5}, result.Short)
+			require.Equal(t, map[string]int{"rate": 5}, *result.Pointer)
+		})
+
+		t.Run("rejects misspelled directives on maps that do not convert", func(t *testing.T) {
+			type StrictTest struct {
+				Scores map[string]int `testfill:"factroy:New"`
+			}
+
+			_, err := testfill.FillWithOptions(StrictTest{}, testfill.WithStrictTags(true))
+
+			require.EqualError(t, err, `testfill: failed to set field Scores: unknown tag directive "factroy"`)
+		})
+
+		t.Run("disabled by default", func(t *testing.T) {
+			type StrictTest struct {
+				Name string `testfill:"factroy:New"`
+			}
+
+			result, err := testfill.Fill(StrictTest{})
+			require.NoError(t, err)
+
+			require.Equal(t, "factroy:New", result.Name)
+		})
+	})
+
+	t.Run("provider values", func(t *testing.T) {
+		t.Run("converts provided values to the field type", func(t *testing.T) {
+			testfill.RegisterProvider(func(key string) (string, error) {
+				values := map[string]string{"userToken": "secret-token", "port": "8080", "hosts": "a,b"}
+				value, ok := values[key]
+				if !ok {
+					return "", fmt.Errorf("missing %s", key)
+				}
+				return value, nil
+			})
+			defer testfill.RegisterProvider(nil)
+
+			type ProviderTest struct {
+				Token string   `testfill:"provider:userToken"`
+				Port  int      `testfill:"provider:port"`
+				Hosts []string `testfill:"provider:hosts"`
+			}
+
+			result, err := testfill.Fill(ProviderTest{})
+			require.NoError(t, err)
+
+			require.Equal(t, "secret-token", result.Token)
+			require.Equal(t, 8080, result.Port)
+			require.Equal(t, []string{"a", "b"}, result.Hosts)
+		})
+
+		t.Run("stores values that look like directives as given", func(t *testing.T) {
+			testfill.RegisterProvider(func(key string) (string, error) {
+				return key, nil
+			})
+			defer testfill.RegisterProvider(nil)
+
+			type ProviderTest struct {
+				Mode  string   `testfill:"provider:incr"`
+				Plan  string   `testfill:"provider:repeat:x"`
+				Steps []string `testfill:"provider:fill,${Name}"`
+			}
+
+			result, err := testfill.Fill(ProviderTest{})
+			require.NoError(t, err)
+
+			require.Equal(t, ProviderTest{Mode: "incr", Plan: "repeat:x", Steps: []string{"fill", "${Name}"}}, result)
+		})
+
+		t.Run("propagates provider errors", func(t *testing.T) {
+			testfill.RegisterProvider(func(key string) (string, error) {
+				return "", fmt.Errorf("missing %s", key)
+			})
+			defer testfill.RegisterProvider(nil)
+
+			type ProviderTest struct {
+				Token string `testfill:"provider:userToken"`
+			}
+
+			_, err := testfill.Fill(ProviderTest{})
+
+			require.EqualError(t, err, "testfill: failed to set field Token: provider failed for key userToken: missing userToken")
+		})
+
+		t.Run("no provider registered", func(t *testing.T) {
+			type ProviderTest struct {
+				Port int `testfill:"provider:port"`
+			}
+
+			_, err := testfill.Fill(ProviderTest{})
+
+			require.EqualError(t, err, "testfill: failed to set field Port: no provider registered for key port")
+		})
+	})
+
+	t.Run("interface implementations", func(t *testing.T) {
+		shapeType := reflect.TypeOf((*Shape)(nil)).Elem()
+		testfill.RegisterImplementation(shapeType, reflect.TypeOf(&Circle{}))
+
+		t.Run("fills interface slices with the registered implementation", func(t *testing.T) {
+			type Drawing struct {
+				Shapes []Shape `testfill:"fill:3"`
+			}
+
+			result, err := testfill.Fill(Drawing{})
+			require.NoError(t, err)
+
+			require.Len(t, result.Shapes, 3)
+			for _, shape := range result.Shapes {
+				require.Equal(t, &Circle{Radius: 2, Label: "circle"}, shape)
+			}
+			require.NotSame(t, result.Shapes[0], result.Shapes[1])
+		})
+
+		t.Run("fills interface slices with variants", func(t *testing.T) {
+			type Drawing struct {
+				Shapes []Shape `testfill:"variants:default,large"`
+			}
+
+			result, err := testfill.Fill(Drawing{})
+			require.NoError(t, err)
+
+			require.Equal(t, []Shape{
+				&Circle{Radius: 2, Label: "circle"},
+				&Circle{Radius: 2, Label: "large circle"},
+			}, result.Shapes)
+		})
+
+		t.Run("fills interface fields", func(t *testing.T) {
+			type Drawing struct {
+				Main     Shape `testfill:"fill"`
+				Existing Shape `testfill:"fill"`
+				Untagged Shape
+			}
+
+			result, err := testfill.Fill(Drawing{Existing: &Circle{Radius: 5}})
+			require.NoError(t, err)
+
+			require.Equal(t, &Circle{Radius: 2, Label: "circle"}, result.Main)
+			require.Equal(t, &Circle{Radius: 5, Label: "circle"}, result.Existing)
+			require.Nil(t, result.Untagged)
+		})
+
+		t.Run("struct implementations", func(t *testing.T) {
+			type Sized interface {
+				Area() float64
+			}
+			testfill.RegisterImplementation(reflect.TypeOf((*Sized)(nil)).Elem(), reflect.TypeOf(Square{}))
+
+			type Drawing struct {
+				Shapes []Sized `testfill:"fill:2"`
+			}
+
+			result, err := testfill.Fill(Drawing{})
+			require.NoError(t, err)
+
+			require.Equal(t, []Sized{Square{Side: 3}, Square{Side: 3}}, result.Shapes)
+		})
+
+		t.Run("no registered implementation", func(t *testing.T) {
+			type Unregistered interface {
+				Unregistered()
+			}
+			type Drawing struct {
+				Shapes []Unregistered `testfill:"fill:2"`
+			}
+
+			_, err := testfill.Fill(Drawing{})
+
+			require.EqualError(t, err, "testfill: failed to set field Shapes: failed to fill slice element 0: no implementation registered for interface testfill_test.Unregistered")
+		})
+
+		t.Run("implementation does not implement the interface", func(t *testing.T) {
+			type Perimeter interface {
+				Perimeter() float64
+			}
+			testfill.RegisterImplementation(reflect.TypeOf((*Perimeter)(nil)).Elem(), reflect.TypeOf(Square{}))
+
+			type Drawing struct {
+				Main Perimeter `testfill:"fill"`
+			}
+
+			_, err := testfill.Fill(Drawing{})
+
+			require.EqualError(t, err, "testfill: failed to fill nested struct Main: implementation testfill_test.Square of interface testfill_test.Perimeter must be a struct or a pointer to a struct implementing it")
+		})
+	})
+
+	t.Run("fill context", func(t *testing.T) {
+		type ctxKey struct{}
+
+		testfill.RegisterFactory("TenantName", func(ctx context.Context) string {
+			return ctx.Value(ctxKey{}).(string)
+		})
+		testfill.RegisterFactory("TenantLabel", func(ctx context.Context, prefix string, n int) string {
+			return fmt.Sprintf("%s-%s-%d", prefix, ctx.Value(ctxKey{}), n)
+		})
+
+		t.Run("passes ctx to factories", func(t *testing.T) {
+			type ContextTest struct {
+				Tenant string `testfill:"factory:TenantName"`
+				Label  string `testfill:"factory:TenantLabel:team:7"`
+			}
+
+			ctx := context.WithValue(context.Background(), ctxKey{}, "acme")
+			result, err := testfill.FillContext(ctx, ContextTest{})
+			require.NoError(t, err)
+
+			require.Equal(t, "acme", result.Tenant)
+			require.Equal(t, "team-acme-7", result.Label)
+		})
+
+		t.Run("ctx parameter does not consume tag arguments", func(t *testing.T) {
+			type ContextTest struct {
+				Label string `testfill:"factory:TenantLabel:team"`
+			}
+
+			_, err := testfill.FillContext(context.Background(), ContextTest{})
+
+			require.EqualError(t, err, "testfill: failed to set field Label: factory function TenantLabel expects 2 arguments, got 1")
+		})
+
+		t.Run("Fill passes a background context", func(t *testing.T) {
+			testfill.RegisterFactory("ContextErr", func(ctx context.Context) string {
+				return fmt.Sprint(ctx.Err())
+			})
+
+			type ContextTest struct {
+				Err string `testfill:"factory:ContextErr"`
+			}
+
+			result, err := testfill.Fill(ContextTest{})
+			require.NoError(t, err)
+
+			require.Equal(t, "<nil>", result.Err)
+		})
+	})
+
+	t.Run("time zones", func(t *testing.T) {
+		newYork, err := time.LoadLocation("America/New_York")
+		require.NoError(t, err)
+
+		t.Run("wall-clock times in a zone", func(t *testing.T) {
+			type ZoneTest struct {
+				Meeting time.Time `testfill:"tz:America/New_York:2023-01-01 09:00:00"`
+				Day     time.Time `testfill:"tz:America/New_York:2023-07-04"`
+			}
+
+			result, err := testfill.Fill(ZoneTest{})
+			require.NoError(t, err)
+
+			require.Equal(t, time.Date(2023, 1, 1, 9, 0, 0, 0, newYork), result.Meeting)
+			require.Equal(t, "EST", result.Meeting.Format("MST"))
+			require.Equal(t, time.Date(2023, 7, 4, 0, 0, 0, 0, newYork), result.Day)
+		})
+
+		t.Run("RFC3339 times are converted to the zone", func(t *testing.T) {
+			type ZoneTest struct {
+				Meeting time.Time `testfill:"tz:America/New_York:2023-01-01T14:00:00Z"`
+			}
+
+			result, err := testfill.Fill(ZoneTest{})
+			require.NoError(t, err)
+
+			require.Equal(t, 9, result.Meeting.Hour())
+			require.Equal(t, newYork, result.Meeting.Location())
+		})
+
+		t.Run("unknown zone", func(t *testing.T) {
+			type ZoneTest struct {
+				Meeting time.Time `testfill:"tz:Mars/Olympus:2023-01-01 09:00:00"`
+			}
+
+			_, err := testfill.Fill(ZoneTest{})
+
+			require.EqualError(t, err, "testfill: failed to set field Meeting: invalid time zone: unknown time zone Mars/Olympus")
+		})
+
+		t.Run("invalid time", func(t *testing.T) {
+			type ZoneTest struct {
+				Meeting time.Time `testfill:"tz:America/New_York:tomorrow"`
+			}
+
+			_, err := testfill.Fill(ZoneTest{})
+
+			require.EqualError(t, err, `testfill: failed to set field Meeting: cannot parse "tomorrow" as a time in America/New_York (expected RFC3339 or a wall-clock time such as 2006-01-02 15:04:05)`)
+		})
+	})
+
+	t.Run("ordered pairs", func(t *testing.T) {
+		t.Run("parses pairs in tag order", func(t *testing.T) {
+			pairs, err := testfill.FillOrderedPairs("zeta:1, alpha:2,mid:3")
+			require.NoError(t, err)
+
+			require.Equal(t, []testfill.Pair{
+				{Key: "zeta", Value: "1"},
+				{Key: "alpha", Value: "2"},
+				{Key: "mid", Value: "3"},
+			}, pairs)
+		})
+
+		t.Run("keeps nested container values intact", func(t *testing.T) {
+			pairs, err := testfill.FillOrderedPairs("x:1;2,y:3")
+			require.NoError(t, err)
+
+			require.Equal(t, []testfill.Pair{{Key: "x", Value: "1;2"}, {Key: "y", Value: "3"}}, pairs)
+		})
+
+		t.Run("invalid pair", func(t *testing.T) {
+			_, err := testfill.FillOrderedPairs("a:1,b")
+
+			require.EqualError(t, err, "invalid map format: b")
+		})
+	})
+
+	t.Run("parse tag", func(t *testing.T) {
+		tests := []struct {
+			tag      string
+			expected testfill.Directive
+		}{
+			{"42", testfill.Directive{Kind: testfill.DirectiveLiteral, Value: "42", Raw: "42"}},
+			{"a:1,b:2", testfill.Directive{Kind: testfill.DirectiveLiteral, Value: "a:1,b:2", Raw: "a:1,b:2"}},
+			{"fill", testfill.Directive{Kind: testfill.DirectiveFill, Raw: "fill"}},
+			{"fill:3", testfill.Directive{Kind: testfill.DirectiveFill, Args: []string{"3"}, Raw: "fill:3"}},
+			{"factory:New", testfill.Directive{Kind: testfill.DirectiveFactory, Name: "New", Args: []string{}, Raw: "factory:New"}},
+			{"factory:New:a:2", testfill.Directive{Kind: testfill.DirectiveFactory, Name: "New", Args: []string{"a", "2"}, Raw: "factory:New:a:2"}},
+			{`unmarshal:{"a":1}`, testfill.Directive{Kind: testfill.DirectiveUnmarshal, Value: `{"a":1}`, Raw: `unmarshal:{"a":1}`}},
+			{"variants:admin, user", testfill.Directive{Kind: testfill.DirectiveVariants, Args: []string{"admin", "user"}, Raw: "variants:admin, user"}},
+			{"provider:token", testfill.Directive{Kind: testfill.DirectiveProvider, Value: "token", Raw: "provider:token"}},
+			{"repeat:3:a:b", testfill.Directive{Kind: testfill.DirectiveRepeat, Args: []string{"3"}, Value: "a:b", Raw: "repeat:3:a:b"}},
+			{"seq", testfill.Directive{Kind: testfill.DirectiveSeq, Raw: "seq"}},
+			{"seq:100", testfill.Directive{Kind: testfill.DirectiveSeq, Value: "100", Raw: "seq:100"}},
+			{"rand:unique", testfill.Directive{Kind: testfill.DirectiveRand, Value: "unique", Raw: "rand:unique"}},
+			{"tz:Europe/Paris:2023-01-01 09:00", testfill.Directive{Kind: testfill.DirectiveTimeZone, Name: "Europe/Paris", Value: "2023-01-01 09:00", Raw: "tz:Europe/Paris:2023-01-01 09:00"}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.tag, func(t *testing.T) {
+				directive, err := testfill.ParseTag(tt.tag)
+				require.NoError(t, err)
+
+				require.Equal(t, tt.expected, directive)
+			})
+		}
+
+		t.Run("malformed directive", func(t *testing.T) {
+			_, err := testfill.ParseTag("repeat:3")
+
+			require.EqualError(t, err, "invalid repeat format: repeat:3 (expected format: repeat:count:value)")
+		})
+	})
+
+	t.Run("conditional fill", func(t *testing.T) {
+		type Plan struct {
+			Price    float64 `testfill:"when:Type==premium:99.99"`
+			Discount int     `testfill:"when:Type!=premium:10"`
+			Type     string  `testfill:"premium" testfill_basic:"basic"`
+			Label    string  `testfill:"when:Type==premium:${Type} plan"`
+		}
+
+		t.Run("fills fields whose condition holds", func(t *testing.T) {
+			result, err := testfill.Fill(Plan{})
+			require.NoError(t, err)
+
+			require.Equal(t, Plan{Price: 99.99, Type: "premium", Label: "premium plan"}, result)
+		})
+
+		t.Run("leaves fields whose condition does not hold zero", func(t *testing.T) {
+			result, err := testfill.FillWithVariant(Plan{}, "basic")
+			require.NoError(t, err)
+
+			require.Equal(t, Plan{Discount: 10, Type: "basic"}, result)
+		})
+
+		t.Run("uses values set before filling", func(t *testing.T) {
+			result, err := testfill.Fill(Plan{Type: "enterprise"})
+			require.NoError(t, err)
+
+			require.Equal(t, Plan{Discount: 10, Type: "enterprise"}, result)
+		})
+
+		t.Run("reports unmet conditions", func(t *testing.T) {
+			actions, err := testfill.Plan(Plan{Type: "basic"})
+			require.NoError(t, err)
+
+			require.Contains(t, actions, testfill.FillAction{Path: "Price", Tag: "when:Type==premium:99.99", Value: "0", Skipped: testfill.SkipWhen})
+		})
+
+		t.Run("unknown field", func(t *testing.T) {
+			type WhenTest struct {
+				Price float64 `testfill:"when:Kind==premium:99.99"`
+			}
+
+			_, err := testfill.Fill(WhenTest{})
+
+			require.EqualError(t, err, "testfill: failed to set field Price: unknown field Kind referenced")
+		})
+
+		t.Run("field promoted through a nil embedded pointer", func(t *testing.T) {
+			type Plan struct {
+				Type string
+			}
+			type WhenTest struct {
+				*Plan
+				Price float64 `testfill:"when:Type==premium:99.99"`
+			}
+
+			_, err := testfill.Fill(WhenTest{})
+			require.EqualError(t, err, "testfill: failed to set field Price: field Type is promoted through a nil embedded pointer")
+
+			result, err := testfill.Fill(WhenTest{Plan: &Plan{Type: "premium"}})
+			require.NoError(t, err)
+			require.Equal(t, 99.99, result.Price)
+		})
+
+		t.Run("invalid condition", func(t *testing.T) {
+			type WhenTest struct {
+				Price float64 `testfill:"when:Type:99.99"`
+			}
+
+			_, err := testfill.Fill(WhenTest{})
+
+			require.EqualError(t, err, "testfill: failed to set field Price: invalid when condition: when:Type:99.99 (expected format: when:Field==value:tag)")
+		})
+	})
+
+	t.Run("factories returning interface values", func(t *testing.T) {
+		testfill.RegisterFactory("AnyValue", func(kind string) interface{} {
+			switch kind {
+			case "vo":
+				return CustomVO{privateField: "from any"}
+			case "int":
+				return 42
+			default:
+				return nil
+			}
+		})
+
+		t.Run("unwraps the dynamic value", func(t *testing.T) {
+			type AnyFactoryTest struct {
+				VO     CustomVO    `testfill:"factory:AnyValue:vo"`
+				Number int         `testfill:"factory:AnyValue:int"`
+				Any    interface{} `testfill:"factory:AnyValue:int"`
+			}
+
+			result, err := testfill.Fill(AnyFactoryTest{})
+			require.NoError(t, err)
+
+			require.Equal(t, CustomVO{privateField: "from any"}, result.VO)
+			require.Equal(t, 42, result.Number)
+			require.Equal(t, 42, result.Any)
+		})
+
+		t.Run("dynamic type mismatch", func(t *testing.T) {
+			type AnyFactoryTest struct {
+				VO CustomVO `testfill:"factory:AnyValue:int"`
+			}
+
+			_, err := testfill.Fill(AnyFactoryTest{})
+
+			require.EqualError(t, err, "testfill: failed to set field VO: factory function AnyValue returns int, but field expects testfill_test.CustomVO")
+		})
+
+		t.Run("nil value", func(t *testing.T) {
+			type AnyFactoryTest struct {
+				VO CustomVO `testfill:"factory:AnyValue:none"`
+			}
+
+			_, err := testfill.Fill(AnyFactoryTest{})
+
+			require.EqualError(t, err, "testfill: failed to set field VO: factory function AnyValue returned nil, but field expects testfill_test.CustomVO")
+		})
+	})
+
+	t.Run("durations", func(t *testing.T) {
+		type DurationTest struct {
+			Timeout time.Duration   `testfill:"1h30m"`
+			Offset  time.Duration   `testfill:"-5s"`
+			Nanos   time.Duration   `testfill:"5000"`
+			Steps   []time.Duration `testfill:"1s,-2m"`
+		}
+
+		result, err := testfill.Fill(DurationTest{})
+		require.NoError(t, err)
+
+		require.Equal(t, 90*time.Minute, result.Timeout)
+		require.Equal(t, -5*time.Second, result.Offset)
+		require.Equal(t, 5000*time.Nanosecond, result.Nanos)
+		require.Equal(t, []time.Duration{time.Second, -2 * time.Minute}, result.Steps)
+	})
+
+	t.Run("clamped values", func(t *testing.T) {
+		t.Run("clamps into range", func(t *testing.T) {
+			type ClampTest struct {
+				Above    int           `testfill:"clamp:0:100:250"`
+				Below    int8          `testfill:"clamp:-10:10:-50"`
+				Inside   int           `testfill:"clamp:0:100:42"`
+				Unsigned uint16        `testfill:"clamp:5:10:1"`
+				Float    float64       `testfill:"clamp:0.5:1.5:2.25"`
+				Timeout  time.Duration `testfill:"clamp:-1m:1m:-5m"`
+			}
+
+			result, err := testfill.Fill(ClampTest{})
+			require.NoError(t, err)
+
+			require.Equal(t, ClampTest{Above: 100, Below: -10, Inside: 42, Unsigned: 5, Float: 1.5, Timeout: -time.Minute}, result)
+		})
+
+		t.Run("malformed clamp", func(t *testing.T) {
+			type ClampTest struct {
+				Value int `testfill:"clamp:0:100"`
+			}
+
+			_, err := testfill.Fill(ClampTest{})
+
+			require.EqualError(t, err, "testfill: failed to set field Value: invalid clamp format: clamp:0:100 (expected format: clamp:min:max:value)")
+		})
+
+		t.Run("invalid bound", func(t *testing.T) {
+			type ClampTest struct {
+				Value uint `testfill:"clamp:-1:100:5"`
+			}
+
+			_, err := testfill.Fill(ClampTest{})
+
+			require.EqualError(t, err, `testfill: failed to set field Value: invalid clamp bound "-1": cannot convert "-1" to uint: strconv.ParseUint: parsing "-1": invalid syntax`)
+		})
+
+		t.Run("minimum greater than maximum", func(t *testing.T) {
+			type ClampTest struct {
+				Value int `testfill:"clamp:100:0:5"`
+			}
+
+			_, err := testfill.Fill(ClampTest{})
+
+			require.EqualError(t, err, "testfill: failed to set field Value: clamp minimum 100 is greater than maximum 0")
+		})
+
+		t.Run("non-numeric field", func(t *testing.T) {
+			type ClampTest struct {
+				Value string `testfill:"clamp:a:z:q"`
+			}
+
+			_, err := testfill.Fill(ClampTest{})
+
+			require.EqualError(t, err, "testfill: failed to set field Value: clamp is not supported for string")
+		})
+	})
+
+	t.Run("post-fill hooks", func(t *testing.T) {
+		t.Run("runs after all fields are filled", func(t *testing.T) {
+			type Name struct {
+				First string `testfill:"Ada"`
+				Last  string `testfill:"Lovelace"`
+				Full  string
+			}
+			type Person struct {
+				Name    Name   `testfill:"fill"`
+				Friends []Name `testfill:"fill:2"`
+			}
+
+			testfill.RegisterPostFill(reflect.TypeOf(Name{}), func(v reflect.Value) error {
+				name := v.Addr().Interface().(*Name)
+				name.Full = name.First + " " + name.Last
+				return nil
+			})
+
+			result, err := testfill.Fill(Person{})
+			require.NoError(t, err)
+
+			require.Equal(t, "Ada Lovelace", result.Name.Full)
+			require.Equal(t, "Ada Lovelace", result.Friends[1].Full)
+		})
+
+		t.Run("errors are reported with the struct path", func(t *testing.T) {
+			type Address struct {
+				City string `testfill:"Nowhere"`
+			}
+			type Person struct {
+				Address Address `testfill:"fill"`
+			}
+
+			testfill.RegisterPostFill(reflect.TypeOf(Address{}), func(v reflect.Value) error {
+				return fmt.Errorf("unknown city %s", v.FieldByName("City"))
+			})
+
+			_, err := testfill.Fill(Person{})
+			require.EqualError(t, err, "testfill: failed to fill nested struct Address: testfill: failed to set field Address: post-fill hook for testfill_test.Address failed: unknown city Nowhere")
+
+			var fieldErr *testfill.FieldError
+			require.ErrorAs(t, err, &fieldErr)
+			require.Equal(t, "Address", fieldErr.Field)
+
+			_, err = testfill.Fill(Address{})
+			require.EqualError(t, err, "testfill: post-fill hook for testfill_test.Address failed: unknown city Nowhere")
+		})
+	})
+
+	t.Run("required fields", func(t *testing.T) {
+		type Account struct {
+			ID    string `testfill:"required"`
+			Owner string `testfill:"required"`
+			Plan  string `testfill:"free"`
+		}
+
+		t.Run("accepts fields set by the caller", func(t *testing.T) {
+			result, err := testfill.Fill(Account{ID: "acc-1", Owner: "alice"})
+			require.NoError(t, err)
+
+			require.Equal(t, Account{ID: "acc-1", Owner: "alice", Plan: "free"}, result)
+		})
+
+		t.Run("fails when a required field is zero", func(t *testing.T) {
+			_, err := testfill.Fill(Account{ID: "acc-1"})
+
+			require.EqualError(t, err, "testfill: failed to set field Owner: required field is not set")
+		})
+
+		t.Run("reports the path of nested required fields", func(t *testing.T) {
+			type Order struct {
+				Accounts []Account `testfill:"fill:1"`
+			}
+
+			_, err := testfill.Fill(Order{})
+
+			var fieldErr *testfill.FieldError
+			require.ErrorAs(t, err, &fieldErr)
+			require.Equal(t, []string{"Accounts", "[0]"}, fieldErr.Path)
+			require.Equal(t, "ID", fieldErr.Field)
+		})
+	})
+
+	t.Run("element overrides", func(t *testing.T) {
+		type Address struct {
+			City string `testfill:"Boston"`
+		}
+		type User struct {
+			Name    string   `testfill:"Alice" testfill_admin:"Root"`
+			Role    string   `testfill:"user" testfill_admin:"admin"`
+			Address *Address `testfill:"fill"`
+		}
+
+		t.Run("overrides fields of specific elements", func(t *testing.T) {
+			type Fixture struct {
+				Users []User `testfill:"variants:default,admin,default" testfill_overrides:"1.Name=Zed; 2.Address.City=Paris"`
+			}
+
+			result, err := testfill.Fill(Fixture{})
+			require.NoError(t, err)
+
+			require.Equal(t, "Alice", result.Users[0].Name)
+			require.Equal(t, "Zed", result.Users[1].Name)
+			require.Equal(t, "admin", result.Users[1].Role)
+			require.Equal(t, "Boston", result.Users[0].Address.City)
+			require.Equal(t, "Paris", result.Users[2].Address.City)
+		})
+
+		t.Run("works with fill:N", func(t *testing.T) {
+			type Fixture struct {
+				Users []User `testfill:"fill:2" testfill_overrides:"0.Role=factory:NewRole"`
+			}
+			testfill.RegisterFactory("NewRole", func() string { return "owner" })
+
+			result, err := testfill.Fill(Fixture{})
+			require.NoError(t, err)
+
+			require.Equal(t, "owner", result.Users[0].Role)
+			require.Equal(t, "user", result.Users[1].Role)
+		})
+
+		t.Run("index out of range", func(t *testing.T) {
+			type Fixture struct {
+				Users []User `testfill:"fill:2" testfill_overrides:"2.Name=Zed"`
+			}
+
+			_, err := testfill.Fill(Fixture{})
+
+			require.EqualError(t, err, "testfill: failed to set field Users: override index 2 out of range for 2 elements")
+		})
+
+		t.Run("unknown field", func(t *testing.T) {
+			type Fixture struct {
+				Users []User `testfill:"fill:2" testfill_overrides:"0.Address.Town=Paris"`
+			}
+
+			_, err := testfill.Fill(Fixture{})
+
+			require.EqualError(t, err, "testfill: failed to set field Users: invalid override 0.Address.Town=Paris: unknown field Town")
+		})
+
+		t.Run("malformed override", func(t *testing.T) {
+			type Fixture struct {
+				Users []User `testfill:"fill:2" testfill_overrides:"Name=Zed"`
+			}
+
+			_, err := testfill.Fill(Fixture{})
+
+			require.EqualError(t, err, "testfill: failed to set field Users: invalid override Name=Zed (expected format: index.Field=value)")
+		})
+	})
+
+	t.Run("template values", func(t *testing.T) {
+		data := map[string]any{"Env": "staging", "Replicas": 3}
+
+		t.Run("renders templates and converts the result", func(t *testing.T) {
+			type Service struct {
+				Name     string   `testfill:"tmpl:{{.Env}}-service"`
+				Replicas int      `testfill:"tmpl:{{.Replicas}}"`
+				Hosts    []string `testfill:"tmpl:{{.Env}}-a,{{.Env}}-b"`
+			}
+
+			result, err := testfill.FillWithOptions(Service{}, testfill.WithTemplateData(data))
+			require.NoError(t, err)
+
+			require.Equal(t, Service{Name: "staging-service", Replicas: 3, Hosts: []string{"staging-a", "staging-b"}}, result)
+		})
+
+		t.Run("stores rendered text that looks like a directive as given", func(t *testing.T) {
+			type Service struct {
+				Source string `testfill:"tmpl:{{.Source}}"`
+				Mode   string `testfill:"tmpl:{{.Mode}}"`
+			}
+			data := map[string]any{"Source": "provider:x", "Mode": "incr"}
+
+			result, err := testfill.FillWithOptions(Service{}, testfill.WithTemplateData(data))
+			require.NoError(t, err)
+
+			require.Equal(t, Service{Source: "provider:x", Mode: "incr"}, result)
+		})
+
+		t.Run("missing key", func(t *testing.T) {
+			type Service struct {
+				Name string `testfill:"tmpl:{{.Region}}-service"`
+			}
+
+			_, err := testfill.FillWithOptions(Service{}, testfill.WithTemplateData(data))
+
+			require.EqualError(t, err, `testfill: failed to set field Name: invalid template "{{.Region}}-service": template: testfill:1:2: executing "testfill" at <.Region>: map has no entry for key "Region"`)
+		})
+
+		t.Run("invalid template", func(t *testing.T) {
+			type Service struct {
+				Name string `testfill:"tmpl:{{.Env"`
+			}
+
+			_, err := testfill.FillWithOptions(Service{}, testfill.WithTemplateData(data))
+
+			require.ErrorContains(t, err, `testfill: failed to set field Name: invalid template "{{.Env": template: testfill:1: unclosed action`)
+		})
+	})
+
+	t.Run("converters", func(t *testing.T) {
+		t.Run("decimal-like TextUnmarshaler types", func(t *testing.T) {
+			type Invoice struct {
+				Amount Decimal   `testfill:"19.99"`
+				Lines  []Decimal `testfill:"1.5,2.25"`
+				Ptr    *Decimal  `testfill:"0.5"`
+			}
+
+			result, err := testfill.Fill(Invoice{})
+			require.NoError(t, err)
+
+			require.Equal(t, Decimal{units: 1999, scale: 2}, result.Amount)
+			require.Equal(t, []Decimal{{units: 15, scale: 1}, {units: 225, scale: 2}}, result.Lines)
+			require.Equal(t, &Decimal{units: 5, scale: 1}, result.Ptr)
+		})
+
+		t.Run("registered converters", func(t *testing.T) {
+			testfill.RegisterConverter(reflect.TypeOf(Money{}), func(s string) (interface{}, error) {
+				value, err := strconv.ParseFloat(strings.TrimPrefix(s, "$"), 64)
+				if err != nil {
+					return nil, err
+				}
+				return Money{Cents: int64(value*100 + 0.5)}, nil
+			})
+			testfill.RegisterFactory("Total", func(a, b Money) Money { return Money{Cents: a.Cents + b.Cents} })
+
+			type Invoice struct {
+				Amount  Money            `testfill:"$19.99"`
+				ByMonth map[string]Money `testfill:"jan:$1,feb:$2.50"`
+				Total   Money            `testfill:"factory:Total:$1:$2"`
+			}
+
+			result, err := testfill.Fill(Invoice{})
+			require.NoError(t, err)
+
+			require.Equal(t, Money{Cents: 1999}, result.Amount)
+			require.Equal(t, map[string]Money{"jan": {Cents: 100}, "feb": {Cents: 250}}, result.ByMonth)
+			require.Equal(t, Money{Cents: 300}, result.Total)
+		})
+
+		t.Run("converter errors", func(t *testing.T) {
+			type Invoice struct {
+				Amount Money `testfill:"free"`
+			}
+
+			_, err := testfill.Fill(Invoice{})
+
+			require.EqualError(t, err, `testfill: failed to set field Amount: cannot convert "free" to testfill_test.Money: strconv.ParseFloat: parsing "free": invalid syntax`)
+		})
+
+		t.Run("converter returning the wrong type", func(t *testing.T) {
+			type Cents int64
+			testfill.RegisterConverter(reflect.TypeOf(Cents(0)), func(s string) (interface{}, error) {
+				return s, nil
+			})
+
+			type Invoice struct {
+				Amount Cents `testfill:"100"`
+			}
+
+			_, err := testfill.Fill(Invoice{})
+
+			require.EqualError(t, err, "testfill: failed to set field Amount: converter for testfill_test.Cents returned string")
+		})
+	})
+
+	t.Run("time keywords", func(t *testing.T) {
+		t.Run("now", func(t *testing.T) {
+			type Event struct {
+				At    time.Time  `testfill:"now"`
+				AtPtr *time.Time `testfill:"now"`
+			}
+
+			before := time.Now()
+			result, err := testfill.Fill(Event{})
+			after := time.Now()
+			require.NoError(t, err)
+
+			require.WithinRange(t, result.At, before, after)
+			require.NotNil(t, result.AtPtr)
+			require.WithinRange(t, *result.AtPtr, before, after)
+		})
+
+		t.Run("unix timestamps", func(t *testing.T) {
+			type Event struct {
+				At    time.Time  `testfill:"unix:1700000000"`
+				AtPtr *time.Time `testfill:"unix:0"`
+				Local time.Time  `testfill:"tz:America/New_York:unix:1700000000"`
+			}
+
+			result, err := testfill.Fill(Event{})
+			require.NoError(t, err)
+
+			require.Equal(t, time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC), result.At)
+			require.Equal(t, time.Unix(0, 0).UTC(), *result.AtPtr)
+			require.True(t, result.Local.Equal(result.At))
+			require.Equal(t, 17, result.Local.Hour())
+		})
+
+		t.Run("unmarshal null keeps pointers nil", func(t *testing.T) {
+			type Event struct {
+				DeletedAt *time.Time `testfill:"unmarshal:null"`
+			}
+
+			result, err := testfill.Fill(Event{})
+			require.NoError(t, err)
+
+			require.Nil(t, result.DeletedAt)
+		})
+
+		t.Run("existing pointers are preserved", func(t *testing.T) {
+			type Event struct {
+				At *time.Time `testfill:"now"`
+			}
+
+			existing := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+			result, err := testfill.Fill(Event{At: &existing})
+			require.NoError(t, err)
+
+			require.Same(t, &existing, result.At)
+			require.Equal(t, existing, *result.At)
+		})
+
+		t.Run("invalid unix timestamp", func(t *testing.T) {
+			type Event struct {
+				At time.Time `testfill:"unix:soon"`
+			}
+
+			_, err := testfill.Fill(Event{})
+
+			require.EqualError(t, err, `testfill: failed to set field At: invalid unix time unix:soon: strconv.ParseInt: parsing "soon": invalid syntax`)
+		})
+	})
+
+	t.Run("fill many", func(t *testing.T) {
+		type Account struct {
+			ID   string `testfill:"rand:unique"`
+			Name string `testfill:"acme"`
+		}
+
+		t.Run("fills inputs of different types in order", func(t *testing.T) {
+			results, err := testfill.FillMany(Bar{}, Account{Name: "globex"}, []Bar{{}, {Integer: 7}})
+			require.NoError(t, err)
+
+			require.Len(t, results, 3)
+			require.Equal(t, Bar{Integer: 42, String: "Olivie Smith"}, results[0])
+			require.Equal(t, "globex", results[1].(Account).Name)
+			require.NotEmpty(t, results[1].(Account).ID)
+			require.Equal(t, []Bar{{Integer: 42, String: "Olivie Smith"}, {Integer: 7, String: "Olivie Smith"}}, results[2])
+		})
+
+		t.Run("unique values are distinct across inputs", func(t *testing.T) {
+			results, err := testfill.FillMany(Account{}, Account{})
+			require.NoError(t, err)
+
+			require.NotEqual(t, results[0].(Account).ID, results[1].(Account).ID)
+		})
+
+		t.Run("reports the index of the failing input", func(t *testing.T) {
+			type Invalid struct {
+				Value int `testfill:"abc"`
+			}
+
+			_, err := testfill.FillMany(Bar{}, Invalid{})
+
+			require.EqualError(t, err, `testfill: failed to fill input 1: testfill: failed to set field Value: cannot convert "abc" to int: strconv.ParseInt: parsing "abc": invalid syntax`)
+		})
+
+		t.Run("rejects non-struct inputs", func(t *testing.T) {
+			_, err := testfill.FillMany(Bar{}, 42)
+
+			require.EqualError(t, err, "testfill: failed to fill input 1: testfill: expected struct, got int")
+
+			_, err = testfill.FillMany(nil)
+
+			require.EqualError(t, err, "testfill: failed to fill input 0: testfill: expected struct, got <nil>")
+		})
+	})
+
+	t.Run("factory panic passthrough", func(t *testing.T) {
+		type PanicTest struct {
+			Value CustomVO `testfill:"factory:PanicFactory"`
+		}
+
+		t.Run("propagates panics when enabled", func(t *testing.T) {
+			require.PanicsWithValue(t, "this factory always panics", func() {
+				_, _ = testfill.FillWithOptions(PanicTest{}, testfill.WithFactoryPanicPassthrough(true))
+			})
+		})
+
+		t.Run("recovers panics by default", func(t *testing.T) {
+			_, err := testfill.FillWithOptions(PanicTest{})
+
+			require.EqualError(t, err, "testfill: failed to set field Value: factory function panicked: this factory always panics")
+		})
+	})
+
+	t.Run("range slices", func(t *testing.T) {
+		t.Run("generates integer ranges", func(t *testing.T) {
+			type RangeTest struct {
+				Simple     []int    `testfill:"range:1..5"`
+				Stepped    []int    `testfill:"range:0..10..2"`
+				Uneven     []int64  `testfill:"range:0..9..4"`
+				Descending []int    `testfill:"range:3..1"`
+				Single     []uint8  `testfill:"range:7..7"`
+				Array      [3]int16 `testfill:"range:-1..1"`
+			}
+
+			result, err := testfill.Fill(RangeTest{})
+			require.NoError(t, err)
+
+			require.Equal(t, []int{1, 2, 3, 4, 5}, result.Simple)
+			require.Equal(t, []int{0, 2, 4, 6, 8, 10}, result.Stepped)
+			require.Equal(t, []int64{0, 4, 8}, result.Uneven)
+			require.Equal(t, []int{3, 2, 1}, result.Descending)
+			require.Equal(t, []uint8{7}, result.Single)
+			require.Equal(t, [3]int16{-1, 0, 1}, result.Array)
+		})
+
+		t.Run("malformed range", func(t *testing.T) {
+			type RangeTest struct {
+				Values []int `testfill:"range:1-5"`
+			}
+
+			_, err := testfill.Fill(RangeTest{})
+
+			require.EqualError(t, err, "testfill: failed to set field Values: invalid range range:1-5 (expected format: range:start..end[..step])")
+		})
+
+		t.Run("step in the wrong direction", func(t *testing.T) {
+			type RangeTest struct {
+				Values []int `testfill:"range:5..1..1"`
+			}
+
+			_, err := testfill.Fill(RangeTest{})
+
+			require.EqualError(t, err, "testfill: failed to set field Values: range range:5..1..1 never reaches its end with step 1")
+		})
+
+		t.Run("out of range for the element type", func(t *testing.T) {
+			type RangeTest struct {
+				Values []uint8 `testfill:"range:254..256"`
+			}
+
+			_, err := testfill.Fill(RangeTest{})
+
+			require.EqualError(t, err, `testfill: failed to set field Values: cannot convert "256" to uint8: strconv.ParseUint: parsing "256": value out of range`)
+		})
+
+		t.Run("non-integer elements", func(t *testing.T) {
+			type RangeTest struct {
+				Values []string `testfill:"range:1..3"`
+			}
+
+			_, err := testfill.Fill(RangeTest{})
+
+			require.EqualError(t, err, "testfill: failed to set field Values: range is not supported for string elements")
+		})
+
+		t.Run("too many values", func(t *testing.T) {
+			type RangeTest struct {
+				Values []int `testfill:"range:0..100000000000000"`
+			}
+
+			_, err := testfill.Fill(RangeTest{})
+
+			require.EqualError(t, err, "testfill: failed to set field Values: range range:0..100000000000000 has more than 16777216 values")
+		})
+
+		t.Run("full-width bounds", func(t *testing.T) {
+			type RangeTest struct {
+				All     []int64 `testfill:"range:-9223372036854775808..9223372036854775807"`
+				Quarter []int64 `testfill:"range:-9223372036854775808..9223372036854775807..4611686018427387904"`
+				Down    []int64 `testfill:"range:9223372036854775807..-9223372036854775808..-9223372036854775808"`
+			}
+
+			_, err := testfill.Fill(RangeTest{})
+			require.EqualError(t, err, "testfill: failed to set field All: range range:-9223372036854775808..9223372036854775807 has more than 16777216 values")
+
+			result, err := testfill.Fill(RangeTest{All: []int64{1}})
+			require.NoError(t, err)
+
+			require.Equal(t, []int64{math.MinInt64, -1 << 62, 0, 1 << 62}, result.Quarter)
+			require.Equal(t, []int64{math.MaxInt64, -1}, result.Down)
+		})
+	})
+
+	t.Run("generic structs", func(t *testing.T) {
+		t.Run("fills generic instantiations", func(t *testing.T) {
+			type GenericTest struct {
+				Ints    Box[int]       `testfill:"fill"`
+				Strings *Box[string]   `testfill:"fill"`
+				Floats  []Box[float64] `testfill:"variants:default,big"`
+				Tree    Tree[int]      `testfill:"fill"`
+			}
+
+			result, err := testfill.Fill(GenericTest{})
+			require.NoError(t, err)
+
+			require.Equal(t, Box[int]{Value: 7, Label: "box"}, result.Ints)
+			require.Equal(t, &Box[string]{Value: "7", Label: "box"}, result.Strings)
+			require.Equal(t, []Box[float64]{{Value: 7, Label: "box"}, {Value: 700, Label: "box"}}, result.Floats)
+			require.Equal(t, Box[int]{Value: 7, Label: "box"}, result.Tree.Root)
+			require.Len(t, result.Tree.Children, 2)
+			require.Equal(t, map[string]Box[int]{"a": {Value: 7, Label: "box"}, "b": {Value: 700, Label: "box"}}, result.Tree.Named)
+		})
+
+		t.Run("fills a generic struct as input", func(t *testing.T) {
+			result, err := testfill.Fill(Box[string]{})
+			require.NoError(t, err)
+
+			require.Equal(t, Box[string]{Value: "7", Label: "box"}, result)
+		})
+
+		t.Run("factories returning generic types", func(t *testing.T) {
+			testfill.RegisterFactory("NewIntBox", func(v int) Box[int] { return Box[int]{Value: v, Label: "factory"} })
+
+			type GenericTest struct {
+				Box   Box[int]    `testfill:"factory:NewIntBox:3"`
+				Wrong Box[string] `testfill:"factory:NewIntBox:3"`
+			}
+
+			_, err := testfill.Fill(GenericTest{})
+			require.EqualError(t, err, "testfill: failed to set field Wrong: factory function NewIntBox returns testfill_test.Box[int], but field expects testfill_test.Box[string]")
+
+			result, err := testfill.Fill(GenericTest{Wrong: Box[string]{Value: "set"}})
+			require.NoError(t, err)
+			require.Equal(t, Box[int]{Value: 3, Label: "factory"}, result.Box)
+		})
+	})
+
+	t.Run("override JSON", func(t *testing.T) {
+		type Address struct {
+			Street string `json:"street" testfill:"Main St"`
+			City   string `json:"city" testfill:"Boston"`
+		}
+		type Person struct {
+			Name    string   `json:"name" testfill:"Alice"`
+			Tags    []string `json:"tags" testfill:"a,b"`
+			Address Address  `json:"address" testfill:"fill"`
+		}
+
+		t.Run("merges sparse JSON onto the filled result", func(t *testing.T) {
+			result, err := testfill.FillWithOptions(Person{}, testfill.WithOverrideJSON([]byte(`{"address":{"city":"Paris"}}`)))
+			require.NoError(t, err)
+
+			require.Equal(t, Person{Name: "Alice", Tags: []string{"a", "b"}, Address: Address{Street: "Main St", City: "Paris"}}, result)
+		})
+
+		t.Run("invalid JSON", func(t *testing.T) {
+			_, err := testfill.FillWithOptions(Person{}, testfill.WithOverrideJSON([]byte(`{"name":1}`)))
+
+			require.ErrorContains(t, err, "testfill: failed to apply override JSON: json: cannot unmarshal number")
+		})
+
+		t.Run("unknown fields with WithDisallowUnknownFields", func(t *testing.T) {
+			_, err := testfill.FillWithOptions(Person{},
+				testfill.WithOverrideJSON([]byte(`{"nme":"Bob"}`)),
+				testfill.WithDisallowUnknownFields(true),
+			)
+
+			require.EqualError(t, err, `testfill: failed to apply override JSON: json: unknown field "nme"`)
+		})
+	})
+
+	t.Run("factory collection arguments", func(t *testing.T) {
+		type Thing struct {
+			IDs  []int
+			Tags []string
+			Pair [2]float64
+		}
+		testfill.RegisterFactory("NewThing", func(ids []int, tags []string) Thing {
+			return Thing{IDs: ids, Tags: tags}
+		})
+		testfill.RegisterFactory("NewPairThing", func(pair [2]float64) Thing {
+			return Thing{Pair: pair}
+		})
+
+		t.Run("splits slice and array arguments", func(t *testing.T) {
+			type FactoryTest struct {
+				Thing Thing `testfill:"factory:NewThing:1|2|3:a|b"`
+				Empty Thing `testfill:"factory:NewThing::solo"`
+				Pair  Thing `testfill:"factory:NewPairThing:1.5|2.5"`
+			}
+
+			result, err := testfill.Fill(FactoryTest{})
+			require.NoError(t, err)
+
+			require.Equal(t, Thing{IDs: []int{1, 2, 3}, Tags: []string{"a", "b"}}, result.Thing)
+			require.Equal(t, Thing{IDs: []int{}, Tags: []string{"solo"}}, result.Empty)
+			require.Equal(t, Thing{Pair: [2]float64{1.5, 2.5}}, result.Pair)
+		})
+
+		t.Run("element conversion errors report the argument index", func(t *testing.T) {
+			type FactoryTest struct {
+				Thing Thing `testfill:"factory:NewThing:1|x:a"`
+			}
+
+			_, err := testfill.Fill(FactoryTest{})
+
+			require.EqualError(t, err, `testfill: failed to set field Thing: factory function NewThing argument 0: cannot convert "x" to int: strconv.ParseInt: parsing "x": invalid syntax`)
+		})
+
+		t.Run("array length mismatch", func(t *testing.T) {
+			type FactoryTest struct {
+				Pair Thing `testfill:"factory:NewPairThing:1|2|3"`
+			}
+
+			_, err := testfill.Fill(FactoryTest{})
+
+			require.EqualError(t, err, "testfill: failed to set field Pair: factory function NewPairThing argument 0: array of length 2 cannot be filled with 3 values")
+		})
+	})
+
+	t.Run("bool aliases", func(t *testing.T) {
+		type Flags struct {
+			Yes     bool            `testfill:"Yes"`
+			On      bool            `testfill:"ON"`
+			One     bool            `testfill:"1"`
+			Short   bool            `testfill:"y"`
+			Off     *bool           `testfill:"off"`
+			List    []bool          `testfill:"yes,no,on,off"`
+			Toggles map[string]bool `testfill:"a:on,b:N"`
+		}
+
+		t.Run("accepts yes/no, y/n and on/off in any case", func(t *testing.T) {
+			result, err := testfill.Fill(Flags{})
+			require.NoError(t, err)
+
+			require.True(t, result.Yes)
+			require.True(t, result.On)
+			require.True(t, result.One)
+			require.True(t, result.Short)
+			require.NotNil(t, result.Off)
+			require.False(t, *result.Off)
+			require.Equal(t, []bool{true, false, true, false}, result.List)
+			require.Equal(t, map[string]bool{"a": true, "b": false}, result.Toggles)
+		})
+
+		t.Run("strict bool rejects aliases", func(t *testing.T) {
+			type Strict struct {
+				Enabled bool `testfill:"yes"`
+			}
+
+			_, err := testfill.FillWithOptions(Strict{}, testfill.WithStrictBool(true))
+
+			require.EqualError(t, err, `testfill: failed to set field Enabled: cannot convert "yes" to bool: strconv.ParseBool: parsing "yes": invalid syntax`)
+		})
+
+		t.Run("strict bool applies to map keys", func(t *testing.T) {
+			type Strict struct {
+				Weights map[bool]int `testfill:"yes:1"`
+			}
+
+			_, err := testfill.FillWithOptions(Strict{}, testfill.WithStrictBool(true))
+			require.EqualError(t, err, "testfill: failed to set field Weights: unsupported map type bool -> int")
+
+			result, err := testfill.Fill(Strict{})
+			require.NoError(t, err)
+			require.Equal(t, map[bool]int{true: 1}, result.Weights)
+		})
+
+		t.Run("strict bool keeps ParseBool values", func(t *testing.T) {
+			type Strict struct {
+				Enabled bool   `testfill:"TRUE"`
+				List    []bool `testfill:"1,f"`
+			}
+
+			result, err := testfill.FillWithOptions(Strict{}, testfill.WithStrictBool(true))
+			require.NoError(t, err)
+
+			require.True(t, result.Enabled)
+			require.Equal(t, []bool{true, false}, result.List)
+		})
+	})
+
+	t.Run("prefixed integer literals", func(t *testing.T) {
+		t.Run("hex, octal and binary prefixes", func(t *testing.T) {
+			type Bits struct {
+				Mask   uint32         `testfill:"0xDEADBEEF"`
+				Mode   int            `testfill:"0o755"`
+				Flags  uint8          `testfill:"0b1010"`
+				Neg    int16          `testfill:"-0x10"`
+				Padded int            `testfill:"007"`
+				List   []int          `testfill:"0x1,0b10,3"`
+				Codes  map[uint8]uint `testfill:"0x1:0xFF,2:0o10"`
+			}
+
+			result, err := testfill.Fill(Bits{})
+			require.NoError(t, err)
+
+			require.Equal(t, uint32(0xDEADBEEF), result.Mask)
+			require.Equal(t, 0o755, result.Mode)
+			require.Equal(t, uint8(0b1010), result.Flags)
+			require.Equal(t, int16(-16), result.Neg)
+			require.Equal(t, 7, result.Padded)
+			require.Equal(t, []int{1, 2, 3}, result.List)
+			require.Equal(t, map[uint8]uint{1: 255, 2: 8}, result.Codes)
+		})
+
+		t.Run("factory arguments", func(t *testing.T) {
+			testfill.RegisterFactory("MaskOf", func(bits []uint8) uint8 {
+				var mask uint8
+				for _, b := range bits {
+					mask |= b
+				}
+				return mask
+			})
+			type FactoryTest struct {
+				Mask uint8 `testfill:"factory:MaskOf:0x10|0b11"`
+			}
+
+			result, err := testfill.Fill(FactoryTest{})
+			require.NoError(t, err)
+
+			require.Equal(t, uint8(0x13), result.Mask)
+		})
+
+		t.Run("out of range hex", func(t *testing.T) {
+			type Bits struct {
+				Value uint8 `testfill:"0x100"`
+			}
+
+			_, err := testfill.Fill(Bits{})
+
+			require.EqualError(t, err, `testfill: failed to set field Value: cannot convert "0x100" to uint8: strconv.ParseUint: parsing "0x100": value out of range`)
+		})
+	})
+
+	t.Run("pointer to container fields", func(t *testing.T) {
+		t.Run("allocates and fills slices and maps", func(t *testing.T) {
+			type Containers struct {
+				Numbers  *[]int          `testfill:"1,2,3"`
+				Counts   *map[string]int `testfill:"a:1"`
+				Repeated *[]string       `testfill:"repeat:2:x"`
+				Bars     *[]Bar          `testfill:"fill:2"`
+			}
+
+			result, err := testfill.Fill(Containers{})
+			require.NoError(t, err)
+
+			require.NotNil(t, result.Numbers)
+			require.Equal(t, []int{1, 2, 3}, *result.Numbers)
+			require.NotNil(t, result.Counts)
+			require.Equal(t, map[string]int{"a": 1}, *result.Counts)
+			require.Equal(t, []string{"x", "x"}, *result.Repeated)
+			require.Equal(t, []Bar{{Integer: 42, String: "Olivie Smith"}, {Integer: 42, String: "Olivie Smith"}}, *result.Bars)
+		})
+
+		t.Run("unmarshal null leaves them nil", func(t *testing.T) {
+			type Containers struct {
+				Numbers *[]int          `testfill:"unmarshal:null"`
+				Counts  *map[string]int `testfill:"unmarshal:null"`
+			}
+
+			result, err := testfill.Fill(Containers{})
+			require.NoError(t, err)
+
+			require.Nil(t, result.Numbers)
+			require.Nil(t, result.Counts)
+		})
+
+		t.Run("preserves set pointers", func(t *testing.T) {
+			type Containers struct {
+				Numbers *[]int `testfill:"1,2,3"`
+			}
+			numbers := []int{9}
+
+			result, err := testfill.Fill(Containers{Numbers: &numbers})
+			require.NoError(t, err)
+
+			require.Same(t, &numbers, result.Numbers)
+			require.Equal(t, []int{9}, *result.Numbers)
+		})
+	})
+
+	t.Run("default variant", func(t *testing.T) {
+		type Account struct {
+			Name string `testfill:"John" testfill_admin:"Jane" testfill_default:"ignored"`
+			Role string `testfill:"user" testfill_admin:"admin"`
+		}
+
+		t.Run("is equivalent to Fill", func(t *testing.T) {
+			expected, err := testfill.Fill(Account{})
+			require.NoError(t, err)
+
+			result, err := testfill.FillWithVariant(Account{}, testfill.DefaultVariant)
+			require.NoError(t, err)
+
+			require.Equal(t, expected, result)
+			require.Equal(t, Account{Name: "John", Role: "user"}, result)
+		})
+
+		t.Run("selects base tags in variants lists", func(t *testing.T) {
+			type Team struct {
+				Members []Account `testfill:"variants:admin,default"`
+			}
+
+			result, err := testfill.Fill(Team{})
+			require.NoError(t, err)
+
+			require.Equal(t, []Account{{Name: "Jane", Role: "admin"}, {Name: "John", Role: "user"}}, result.Members)
+		})
+	})
+
+	t.Run("FillWithVariants", func(t *testing.T) {
+		type Location struct {
+			Currency string `testfill:"USD" testfill_eu:"EUR"`
+		}
+		type Member struct {
+			Role     string   `testfill:"user" testfill_admin:"admin"`
+			Region   string   `testfill:"us" testfill_eu:"eu"`
+			Label    string   `testfill:"plain" testfill_admin:"admin-label" testfill_eu:"eu-label"`
+			Location Location `testfill:"fill"`
+		}
+
+		t.Run("composes variants in priority order", func(t *testing.T) {
+			result, err := testfill.FillWithVariants(Member{}, "admin", "eu")
+			require.NoError(t, err)
+
+			require.Equal(t, Member{Role: "admin", Region: "eu", Label: "admin-label", Location: Location{Currency: "EUR"}}, result)
+		})
+
+		t.Run("earlier variants win", func(t *testing.T) {
+			result, err := testfill.FillWithVariants(Member{}, "eu", "admin")
+			require.NoError(t, err)
+
+			require.Equal(t, "eu-label", result.Label)
+		})
+
+		t.Run("no variants is equivalent to Fill", func(t *testing.T) {
+			result, err := testfill.FillWithVariants(Member{})
+			require.NoError(t, err)
+
+			require.Equal(t, Member{Role: "user", Region: "us", Label: "plain", Location: Location{Currency: "USD"}}, result)
+		})
+
+		t.Run("default stops the lookup", func(t *testing.T) {
+			result, err := testfill.FillWithVariants(Member{}, "default", "admin")
+			require.NoError(t, err)
+
+			require.Equal(t, "user", result.Role)
+		})
+
+		t.Run("fills slices of structs", func(t *testing.T) {
+			result, err := testfill.FillWithVariants([]Member{{}, {Role: "owner"}}, "admin", "eu")
+			require.NoError(t, err)
+
+			require.Equal(t, "admin", result[0].Role)
+			require.Equal(t, "eu", result[0].Region)
+			require.Equal(t, "owner", result[1].Role)
+		})
+	})
+
+	t.Run("oneof", func(t *testing.T) {
+		type Paint struct {
+			Color string `testfill:"oneof:red,green,blue"`
+			Size  int    `testfill:"oneof:1,2"`
+		}
+
+		t.Run("uses the first option outside slices", func(t *testing.T) {
+			result, err := testfill.Fill(Paint{})
+			require.NoError(t, err)
+
+			require.Equal(t, Paint{Color: "red", Size: 1}, result)
+		})
+
+		t.Run("picks the option at the variant position", func(t *testing.T) {
+			type Palette struct {
+				Paints []Paint `testfill:"variants:primary,secondary"`
+			}
+
+			result, err := testfill.Fill(Palette{})
+			require.NoError(t, err)
+
+			require.Equal(t, []Paint{{Color: "red", Size: 1}, {Color: "green", Size: 2}}, result.Paints)
+		})
+
+		t.Run("out of range element", func(t *testing.T) {
+			type Palette struct {
+				Paints []Paint `testfill:"fill:3"`
+			}
+
+			_, err := testfill.Fill(Palette{})
+
+			require.EqualError(t, err, "testfill: failed to set field Paints: failed to fill slice element 2: testfill: failed to set field Paints[2].Size: oneof:1,2 has no option for element 2")
+		})
+
+		t.Run("parses the tag", func(t *testing.T) {
+			directive, err := testfill.ParseTag("oneof:red, green")
+			require.NoError(t, err)
+
+			require.Equal(t, testfill.DirectiveOneOf, directive.Kind)
+			require.Equal(t, []string{"red", "green"}, directive.Args)
+		})
+	})
+
+	t.Run("FillFromCSV", func(t *testing.T) {
+		type Row struct {
+			Name    string  `testfill:"anonymous"`
+			Age     int     `csv:"age" testfill:"18"`
+			Score   float64 `csv:"score"`
+			Active  bool    `csv:"active" testfill:"true"`
+			Country string  `csv:"-" testfill:"BR"`
+		}
+
+		t.Run("sets columns and fills the gaps", func(t *testing.T) {
+			result, err := testfill.FillFromCSV[Row](
+				[]string{"Name", "age", "score", "active", "Country", "extra"},
+				[]string{"alice", "42", "9.5", "", "US", "ignored"},
+			)
+			require.NoError(t, err)
+
+			require.Equal(t, Row{Name: "alice", Age: 42, Score: 9.5, Active: true, Country: "BR"}, result)
+		})
+
+		t.Run("mismatched row length", func(t *testing.T) {
+			_, err := testfill.FillFromCSV[Row]([]string{"Name", "age"}, []string{"alice"})
+
+			require.EqualError(t, err, "testfill: CSV row has 1 values for 2 columns")
+		})
+
+		t.Run("conversion errors name the field", func(t *testing.T) {
+			_, err := testfill.FillFromCSV[Row]([]string{"age"}, []string{"old"})
+
+			var fieldErr *testfill.FieldError
+			require.ErrorAs(t, err, &fieldErr)
+			require.Equal(t, "Age", fieldErr.Field)
+			require.EqualError(t, err, `testfill: failed to set field Age: cannot convert "old" to int: strconv.ParseInt: parsing "old": invalid syntax`)
+		})
+
+		t.Run("rejects non-struct types", func(t *testing.T) {
+			_, err := testfill.FillFromCSV[string]([]string{"a"}, []string{"b"})
+
+			require.EqualError(t, err, "testfill: expected struct, got string")
+		})
+	})
+
+	t.Run("fill:shallow", func(t *testing.T) {
+		type Wrapper struct {
+			Shallow    Baz  `testfill:"fill:shallow"`
+			ShallowPtr *Baz `testfill:"fill:shallow"`
+			Deep       Baz  `testfill:"fill"`
+		}
+
+		t.Run("fills the immediate struct without recursing", func(t *testing.T) {
+			result, err := testfill.Fill(Wrapper{})
+			require.NoError(t, err)
+
+			require.Equal(t, Baz{Name: "Deep Nested", Value: 100}, result.Shallow)
+			require.Equal(t, &Baz{Name: "Deep Nested", Value: 100}, result.ShallowPtr)
+			require.Equal(t, Bar{Integer: 42, String: "Olivie Smith"}, result.Deep.NestedBar)
+		})
+
+		t.Run("plan reports the skipped children", func(t *testing.T) {
+			actions, err := testfill.Plan(Wrapper{})
+			require.NoError(t, err)
+
+			require.Contains(t, actions, testfill.FillAction{
+				Path:    "Shallow.NestedBar",
+				Tag:     "fill",
+				Value:   "{0 }",
+				Skipped: testfill.SkipShallow,
+			})
+		})
+	})
+
+	t.Run("tag macros", func(t *testing.T) {
+		testfill.RegisterMacro("email", `unmarshal:"x@example.com"`)
+		testfill.RegisterMacro("premium", "when:Plan==premium:99.99")
+		testfill.RegisterMacro("home", "fill")
+
+		t.Run("expands macros before processing", func(t *testing.T) {
+			type Customer struct {
+				Email   string  `testfill:"@email"`
+				Plan    string  `testfill:"premium"`
+				Price   float64 `testfill:"@premium"`
+				Address Bar     `testfill:"@home"`
+			}
+
+			result, err := testfill.Fill(Customer{})
+			require.NoError(t, err)
+
+			require.Equal(t, Customer{
+				Email:   "x@example.com",
+				Plan:    "premium",
+				Price:   99.99,
+				Address: Bar{Integer: 42, String: "Olivie Smith"},
+			}, result)
+		})
+
+		t.Run("expands variant tags", func(t *testing.T) {
+			type Customer struct {
+				Email string `testfill:"plain@example.com" testfill_admin:"@email"`
+			}
+
+			result, err := testfill.FillWithVariant(Customer{}, "admin")
+			require.NoError(t, err)
+
+			require.Equal(t, "x@example.com", result.Email)
+		})
+
+		t.Run("unknown macro", func(t *testing.T) {
+			type Customer struct {
+				Email string `testfill:"@mail"`
+			}
+
+			_, err := testfill.Fill(Customer{})
+
+			require.EqualError(t, err, "testfill: failed to set field Email: unknown macro mail")
+		})
+	})
+
+	t.Run("json.RawMessage", func(t *testing.T) {
+		t.Run("stores plain and unmarshal tags verbatim", func(t *testing.T) {
+			type Event struct {
+				Payload  json.RawMessage  `testfill:"{\"id\":1,\"tags\":[\"a\",\"b\"]}"`
+				Raw      json.RawMessage  `testfill:"unmarshal:{\"ok\":true}"`
+				Pointer  *json.RawMessage `testfill:"[1, 2]"`
+				Optional json.RawMessage  `testfill:"null"`
+			}
+
+			result, err := testfill.Fill(Event{})
+			require.NoError(t, err)
+
+			require.Equal(t, json.RawMessage(`{"id":1,"tags":["a","b"]}`), result.Payload)
+			require.Equal(t, json.RawMessage(`{"ok":true}`), result.Raw)
+			require.Equal(t, json.RawMessage(`[1, 2]`), *result.Pointer)
+			require.Equal(t, json.RawMessage(`null`), result.Optional)
+		})
+
+		t.Run("rejects invalid JSON", func(t *testing.T) {
+			type Event struct {
+				Payload json.RawMessage `testfill:"a,b"`
+			}
+
+			_, err := testfill.Fill(Event{})
+
+			require.EqualError(t, err, "testfill: failed to set field Payload: invalid JSON for json.RawMessage: a,b")
+		})
+	})
+
+	t.Run("factory registry", func(t *testing.T) {
+		type Fixture struct {
+			Name string `testfill:"factory:RegistryName"`
+		}
+
+		t.Run("unregistered factories are not found", func(t *testing.T) {
+			testfill.RegisterFactory("RegistryName", func() string { return "first" })
+			testfill.UnregisterFactory("RegistryName")
+
+			_, err := testfill.Fill(Fixture{})
+
+			require.EqualError(t, err, "testfill: failed to set field Name: factory function RegistryName not found")
+		})
+
+		t.Run("re-registering replaces the factory", func(t *testing.T) {
+			testfill.RegisterFactory("RegistryName", func() string { return "first" })
+			testfill.RegisterFactory("RegistryName", func() string { return "second" })
+
+			result, err := testfill.Fill(Fixture{})
+			require.NoError(t, err)
+
+			require.Equal(t, "second", result.Name)
+		})
+
+		t.Run("registration is safe while filling", func(t *testing.T) {
+			testfill.RegisterFactory("RegistryName", func() string { return "name" })
+
+			var wg sync.WaitGroup
+			for i := 0; i < 8; i++ {
+				wg.Add(2)
+				go func(i int) {
+					defer wg.Done()
+					testfill.RegisterFactory(fmt.Sprintf("RegistryOther%d", i), func() string { return "other" })
+				}(i)
+				go func() {
+					defer wg.Done()
+					result, err := testfill.Fill(Fixture{})
+					require.NoError(t, err)
+					require.Equal(t, "name", result.Name)
+				}()
+			}
+			wg.Wait()
+		})
+	})
+
+	t.Run("WithFactoryDefaults", func(t *testing.T) {
+		testfill.RegisterFactory("Greeting", func(text string, times int, loud bool) string {
+			greeting := strings.Repeat(text, times+1)
+			if loud {
+				return strings.ToUpper(greeting)
+			}
+			return greeting
+		})
+		type Message struct {
+			Text string `testfill:"factory:Greeting:hello"`
+		}
+
+		t.Run("zero-values missing trailing arguments", func(t *testing.T) {
+			result, err := testfill.FillWithOptions(Message{}, testfill.WithFactoryDefaults(true))
+			require.NoError(t, err)
+
+			require.Equal(t, "hello", result.Text)
+		})
+
+		t.Run("still rejects extra arguments", func(t *testing.T) {
+			type Extra struct {
+				Text string `testfill:"factory:Greeting:hi:1:true:x"`
+			}
+
+			_, err := testfill.FillWithOptions(Extra{}, testfill.WithFactoryDefaults(true))
+
+			require.EqualError(t, err, "testfill: failed to set field Text: factory function Greeting expects 3 arguments, got 4")
+		})
+
+		t.Run("requires every argument by default", func(t *testing.T) {
+			_, err := testfill.Fill(Message{})
+
+			require.EqualError(t, err, "testfill: failed to set field Text: factory function Greeting expects 3 arguments, got 1")
+		})
+	})
+
+	t.Run("copy directive", func(t *testing.T) {
+		type UserID int64
+		type Account struct {
+			Mirror    string `testfill:"copy:Email"`
+			Email     string `testfill:"${Name}@example.com"`
+			Name      string `testfill:"ada"`
+			ID        int64  `testfill:"7"`
+			OwnerID   UserID `testfill:"copy:ID"`
+			BackupID  UserID `testfill:"copy:OwnerID"`
+			Preserved string `testfill:"copy:Name"`
+		}
+
+		t.Run("copies resolved sibling values", func(t *testing.T) {
+			result, err := testfill.Fill(Account{Preserved: "kept"})
+			require.NoError(t, err)
+
+			require.Equal(t, "ada@example.com", result.Mirror)
+			require.Equal(t, UserID(7), result.OwnerID)
+			require.Equal(t, UserID(7), result.BackupID)
+			require.Equal(t, "kept", result.Preserved)
+		})
+
+		t.Run("unknown source", func(t *testing.T) {
+			type Broken struct {
+				Mirror string `testfill:"copy:Missing"`
+			}
+
+			_, err := testfill.Fill(Broken{})
+
+			require.EqualError(t, err, "testfill: failed to set field Mirror: unknown field Missing referenced")
+		})
+
+		t.Run("source promoted through a nil embedded pointer", func(t *testing.T) {
+			type Embedded struct {
+				Name string
+			}
+			type Broken struct {
+				*Embedded
+				Mirror string `testfill:"copy:Name"`
+			}
+
+			_, err := testfill.Fill(Broken{})
+			require.EqualError(t, err, "testfill: failed to set field Mirror: field Name is promoted through a nil embedded pointer")
+
+			result, err := testfill.Fill(Broken{Embedded: &Embedded{Name: "jane"}})
+			require.NoError(t, err)
+			require.Equal(t, "jane", result.Mirror)
+		})
+
+		t.Run("incompatible types", func(t *testing.T) {
+			type Broken struct {
+				Count int    `testfill:"3"`
+				Label string `testfill:"copy:Count"`
+			}
+
+			_, err := testfill.Fill(Broken{})
+
+			require.EqualError(t, err, "testfill: failed to set field Label: cannot copy field Count of type int to string")
+		})
+
+		t.Run("circular copies", func(t *testing.T) {
+			type Broken struct {
+				A string `testfill:"copy:B"`
+				B string `testfill:"copy:A"`
+			}
+
+			_, err := testfill.Fill(Broken{})
+
+			require.EqualError(t, err, "testfill: failed to set field A: circular reference to field A")
+		})
+	})
+
+	t.Run("nested containers of structs", func(t *testing.T) {
+		type Member struct {
+			Name string `testfill:"member" testfill_admin:"admin"`
+		}
+
+		t.Run("fills structs inside nested containers", func(t *testing.T) {
+			type Teams struct {
+				Groups []map[string]Member `testfill:"a=fill;b=admin,c=fill"`
+				Lists  map[string][]Member `testfill:"x:fill;admin"`
+			}
+
+			result, err := testfill.Fill(Teams{})
+			require.NoError(t, err)
+
+			require.Equal(t, []map[string]Member{
+				{"a": {Name: "member"}, "b": {Name: "admin"}},
+				{"c": {Name: "member"}},
+			}, result.Groups)
+			require.Equal(t, map[string][]Member{"x": {{Name: "member"}, {Name: "admin"}}}, result.Lists)
+		})
+
+		t.Run("errors name the nested element", func(t *testing.T) {
+			type Bar struct {
+				Count int `testfill:"many"`
+			}
+
+			_, err := testfill.Fill(struct {
+				Groups []map[string]Bar `testfill:"a=fill"`
+			}{})
+
+			require.ErrorContains(t, err, "Groups[0][a].Count")
+		})
+
+		t.Run("rejects fill with a precise error", func(t *testing.T) {
+			type Broken struct {
+				Groups []map[string]Member `testfill:"fill"`
+			}
+			type BrokenCount struct {
+				Counts []map[string]Member `testfill:"fill:2"`
+			}
+
+			_, err := testfill.Fill(Broken{})
+			require.EqualError(t, err, "testfill: failed to set field Groups: []map[string]testfill_test.Member cannot be filled with fill: give each nested struct as fill or a variant name, e.g. a=fill;b=admin")
+
+			_, err = testfill.Fill(BrokenCount{})
+			require.EqualError(t, err, "testfill: failed to set field Counts: []map[string]testfill_test.Member cannot be filled with fill:2: give each nested struct as fill or a variant name, e.g. a=fill;b=admin")
+		})
+	})
+
+	t.Run("WithSkip", func(t *testing.T) {
+		type Secrets struct {
+			Token string `testfill:"secret"`
+		}
+		type Profile struct {
+			Name    string  `testfill:"ada"`
+			Alias   string  `testfill:"copy:Name"`
+			Secrets Secrets `testfill:"fill"`
+			Foo     Foo     `testfill:"fill"`
+			Friends []Bar   `testfill:"fill:2"`
+		}
+
+		t.Run("leaves listed paths untouched", func(t *testing.T) {
+			result, err := testfill.FillWithOptions(Profile{}, testfill.WithSkip("Secrets", "Foo.NestedStructWithFillTag.String", "Alias"))
+			require.NoError(t, err)
+
+			require.Equal(t, "ada", result.Name)
+			require.Empty(t, result.Alias)
+			require.Equal(t, Secrets{}, result.Secrets)
+			require.Equal(t, Bar{Integer: 42}, result.Foo.NestedStructWithFillTag)
+			require.Equal(t, "Olivie Smith", result.Foo.NestedPointerWithFillTag.String)
+		})
+
+		t.Run("matches slice elements with or without indexes", func(t *testing.T) {
+			result, err := testfill.FillWithOptions(Profile{}, testfill.WithSkip("Friends[0].Integer", "Friends.String"))
+			require.NoError(t, err)
+
+			require.Equal(t, []Bar{{}, {Integer: 42}}, result.Friends)
+		})
+
+	})
+
+	t.Run("WithOnly", func(t *testing.T) {
+		type Profile struct {
+			Name    string `testfill:"ada"`
+			Email   string `testfill:"ada@example.com"`
+			Foo     Foo    `testfill:"fill"`
+			Friends []Bar  `testfill:"fill:2"`
+		}
+
+		t.Run("fills only the listed paths", func(t *testing.T) {
+			result, err := testfill.FillWithOptions(Profile{}, testfill.WithOnly("Name", "Foo.DeeplyNestedWithFillTag.NestedBar"))
+			require.NoError(t, err)
+
+			require.Equal(t, "ada", result.Name)
+			require.Empty(t, result.Email)
+			require.Nil(t, result.Friends)
+			require.Equal(t, Bar{}, result.Foo.NestedStructWithFillTag)
+			require.Nil(t, result.Foo.NestedPointerWithFillTag)
+			require.Equal(t, Baz{NestedBar: Bar{Integer: 42, String: "Olivie Smith"}}, result.Foo.DeeplyNestedWithFillTag)
+		})
+
+		t.Run("reaches fields of slice elements", func(t *testing.T) {
+			result, err := testfill.FillWithOptions(Profile{}, testfill.WithOnly("Friends.Integer"))
+			require.NoError(t, err)
+
+			require.Equal(t, []Bar{{Integer: 42}, {Integer: 42}}, result.Friends)
+			require.Empty(t, result.Name)
+		})
+	})
+
+	t.Run("enum directive", func(t *testing.T) {
+		type Account struct {
+			Status   string  `testfill:"enum:active:active,inactive,pending"`
+			Level    int     `testfill:"enum:2:1,2,3"`
+			Optional *string `testfill:"enum:pending:active,pending"`
+		}
+
+		t.Run("fills allowed values", func(t *testing.T) {
+			result, err := testfill.Fill(Account{})
+			require.NoError(t, err)
+
+			require.Equal(t, "active", result.Status)
+			require.Equal(t, 2, result.Level)
+			require.Equal(t, "pending", *result.Optional)
+		})
+
+		t.Run("accepts allowed caller values", func(t *testing.T) {
+			active := "active"
+			result, err := testfill.Fill(Account{Status: "inactive", Level: 3, Optional: &active})
+			require.NoError(t, err)
+
+			require.Equal(t, "inactive", result.Status)
+			require.Equal(t, 3, result.Level)
+		})
+
+		t.Run("rejects caller values outside the set", func(t *testing.T) {
+			_, err := testfill.Fill(Account{Level: 7})
+
+			require.EqualError(t, err, "testfill: failed to set field Level: value 7 is not one of the allowed values 1,2,3")
+		})
+
+		t.Run("rejects tag values outside the set", func(t *testing.T) {
+			type Broken struct {
+				Status string `testfill:"enum:deleted:active,inactive"`
+			}
+
+			_, err := testfill.Fill(Broken{})
+
+			require.EqualError(t, err, "testfill: failed to set field Status: value deleted is not one of the allowed values active,inactive")
+		})
+
+		t.Run("invalid format", func(t *testing.T) {
+			_, err := testfill.ParseTag("enum:active")
+
+			require.EqualError(t, err, "invalid enum format: enum:active (expected format: enum:value:allowed1,allowed2)")
+		})
+	})
+
+	t.Run("uuid", func(t *testing.T) {
+		uuidPattern := `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`
+		type Entity struct {
+			ID      string  `testfill:"uuid"`
+			Other   string  `testfill:"uuid"`
+			Typed   UUID    `testfill:"uuid"`
+			Pointer *string `testfill:"uuid"`
+		}
+
+		t.Run("generates version 4 UUIDs", func(t *testing.T) {
+			result, err := testfill.Fill(Entity{})
+			require.NoError(t, err)
+
+			require.Regexp(t, uuidPattern, result.ID)
+			require.Regexp(t, uuidPattern, *result.Pointer)
+			require.NotEqual(t, result.ID, result.Other)
+			require.NotEqual(t, UUID{}, result.Typed)
+			require.Equal(t, byte(0x40), result.Typed[6]&0xf0)
+		})
+
+		t.Run("is reproducible with WithSeed", func(t *testing.T) {
+			first, err := testfill.FillWithOptions(Entity{}, testfill.WithSeed(42))
+			require.NoError(t, err)
+			second, err := testfill.FillWithOptions(Entity{}, testfill.WithSeed(42))
+			require.NoError(t, err)
+			third, err := testfill.FillWithOptions(Entity{}, testfill.WithSeed(7))
+			require.NoError(t, err)
+
+			require.Equal(t, first, second)
+			require.NotEqual(t, first.ID, third.ID)
+		})
+
+		t.Run("WithSeed also seeds rand:unique", func(t *testing.T) {
+			type Random struct {
+				Value string `testfill:"rand:unique"`
+			}
+
+			first, err := testfill.FillWithOptions(Random{}, testfill.WithSeed(1))
+			require.NoError(t, err)
+			second, err := testfill.FillWithOptions(Random{}, testfill.WithSeed(1))
+			require.NoError(t, err)
+
+			require.Equal(t, first, second)
+		})
+	})
+
+	t.Run("incr counters", func(t *testing.T) {
+		type Order struct {
+			ID     int    `testfill:"incr"`
+			LineID uint16 `testfill:"incr:lines"`
+		}
+
+		t.Run("increase across fills and namespaces", func(t *testing.T) {
+			testfill.ResetCounters()
+
+			first, err := testfill.Fill(Order{})
+			require.NoError(t, err)
+			second, err := testfill.Fill(Order{})
+			require.NoError(t, err)
+			orders, err := testfill.Fill([]Order{{}, {}})
+			require.NoError(t, err)
+
+			require.Equal(t, Order{ID: 1, LineID: 1}, first)
+			require.Equal(t, Order{ID: 2, LineID: 2}, second)
+			require.Equal(t, []Order{{ID: 3, LineID: 3}, {ID: 4, LineID: 4}}, orders)
+		})
+
+		t.Run("ResetCounters restarts sequences", func(t *testing.T) {
+			_, err := testfill.Fill(Order{})
+			require.NoError(t, err)
+
+			testfill.ResetCounters()
+			result, err := testfill.Fill(Order{})
+			require.NoError(t, err)
+
+			require.Equal(t, Order{ID: 1, LineID: 1}, result)
+		})
+
+		t.Run("is safe for concurrent fills", func(t *testing.T) {
+			testfill.ResetCounters()
+
+			var wg sync.WaitGroup
+			ids := make([]int, 20)
+			for i := range ids {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					result, err := testfill.Fill(Order{})
+					require.NoError(t, err)
+					ids[i] = result.ID
+				}(i)
+			}
+			wg.Wait()
+
+			require.ElementsMatch(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}, ids)
+		})
+
+		t.Run("rejects non-integer fields", func(t *testing.T) {
+			type Broken struct {
+				Name string `testfill:"incr"`
+			}
+
+			_, err := testfill.Fill(Broken{})
+
+			require.EqualError(t, err, "testfill: failed to set field Name: incr is not supported for string")
+		})
+	})
+
+	t.Run("pre-set pointers with nil inner fill pointers", func(t *testing.T) {
+		type Leaf struct {
+			Value string `testfill:"leaf"`
+		}
+		type Middle struct {
+			Name string `testfill:"middle"`
+			Leaf *Leaf  `testfill:"fill"`
+		}
+		type Outer struct {
+			Middle *Middle `testfill:"fill"`
+		}
+		type Root struct {
+			Outer *Outer `testfill:"fill"`
+		}
+
+		t.Run("allocates and fills every level below the set pointer", func(t *testing.T) {
+			input := Root{Outer: &Outer{}}
+
+			result, err := testfill.Fill(input)
+			require.NoError(t, err)
+
+			require.Same(t, input.Outer, result.Outer)
+			require.Equal(t, &Middle{Name: "middle", Leaf: &Leaf{Value: "leaf"}}, result.Outer.Middle)
+		})
+
+		t.Run("keeps set values at every level", func(t *testing.T) {
+			leaf := &Leaf{Value: "mine"}
+			input := Root{Outer: &Outer{Middle: &Middle{Leaf: leaf}}}
+
+			result, err := testfill.Fill(input)
+			require.NoError(t, err)
+
+			require.Equal(t, "middle", result.Outer.Middle.Name)
+			require.Same(t, leaf, result.Outer.Middle.Leaf)
+			require.Equal(t, "mine", result.Outer.Middle.Leaf.Value)
+		})
+	})
+
+	t.Run("tag comments", func(t *testing.T) {
+		t.Run("strips comments from literal values", func(t *testing.T) {
+			type Answers struct {
+				Number int            `testfill:"42 #the answer"`
+				Tags   []string       `testfill:"a,b #two tags"`
+				Scores map[string]int `testfill:"x:1 #inline"`
+				Color  string         `testfill:"\\#fff # escaped"`
+				Plain  string         `testfill:"no comment"`
+			}
+
+			result, err := testfill.Fill(Answers{})
+			require.NoError(t, err)
+
+			require.Equal(t, Answers{
+				Number: 42,
+				Tags:   []string{"a", "b"},
+				Scores: map[string]int{"x": 1},
+				Color:  "#fff",
+				Plain:  "no comment",
+			}, result)
+		})
+
+		t.Run("keeps # not preceded by whitespace", func(t *testing.T) {
+			type Links struct {
+				Color string `testfill:"#ff0000"`
+				Docs  string `testfill:"https://x/docs#install"`
+				Lang  string `testfill:"C#"`
+			}
+
+			result, err := testfill.Fill(Links{})
+			require.NoError(t, err)
+
+			require.Equal(t, Links{Color: "#ff0000", Docs: "https://x/docs#install", Lang: "C#"}, result)
+		})
+
+		t.Run("strips comments before expanding references", func(t *testing.T) {
+			type Job struct {
+				Name  string `testfill:"C #language"`
+				Title string `testfill:"${Name} developer #title"`
+				Alias string `testfill:"when:Name==C:${Name} dev #alias"`
+			}
+
+			result, err := testfill.Fill(Job{Name: "C #"})
+			require.NoError(t, err)
+
+			require.Equal(t, "C # developer", result.Title)
+
+			result, err = testfill.Fill(Job{})
+			require.NoError(t, err)
+
+			require.Equal(t, Job{Name: "C", Title: "C developer", Alias: "C dev"}, result)
+		})
+
+		t.Run("leaves directives untouched", func(t *testing.T) {
+			type Config struct {
+				Settings map[string]string `testfill:"unmarshal:{\"color\":\"#fff\"}"`
+			}
+
+			result, err := testfill.Fill(Config{})
+			require.NoError(t, err)
+
+			require.Equal(t, map[string]string{"color": "#fff"}, result.Settings)
+		})
+	})
+
+	t.Run("FillInPlace", func(t *testing.T) {
+		t.Run("fills the target without copying", func(t *testing.T) {
+			target := Bar{Integer: 7}
+
+			err := testfill.FillInPlace(&target)
+			require.NoError(t, err)
+
+			require.Equal(t, Bar{Integer: 7, String: "Olivie Smith"}, target)
+		})
+
+		t.Run("fills slices and accepts options", func(t *testing.T) {
+			targets := []Bar{{}, {}}
+
+			err := testfill.FillInPlace(&targets, testfill.WithSkip("[1].String"))
+			require.NoError(t, err)
+
+			require.Equal(t, []Bar{{Integer: 42, String: "Olivie Smith"}, {Integer: 42}}, targets)
+		})
+
+		t.Run("rejects nil and non-struct targets", func(t *testing.T) {
+			var missing *Bar
+			require.EqualError(t, testfill.FillInPlace(missing), "testfill: cannot fill through a nil *testfill_test.Bar")
+
+			number := 5
+			require.EqualError(t, testfill.FillInPlace(&number), "testfill: expected struct, got int")
+		})
+	})
+
+	t.Run("Defaulter", func(t *testing.T) {
+		t.Run("applies method defaults before tags", func(t *testing.T) {
+			result, err := testfill.Fill(Server{})
+			require.NoError(t, err)
+
+			require.Equal(t, Server{
+				Host:    "localhost",
+				Port:    9090,
+				Headers: map[string]string{"Accept": "application/json"},
+				Limits:  []int{10, 100},
+			}, result)
+		})
+
+		t.Run("keeps caller values", func(t *testing.T) {
+			result, err := testfill.Fill(Server{Port: 1})
+			require.NoError(t, err)
+
+			require.Equal(t, 1, result.Port)
+			require.Equal(t, []int{10, 100}, result.Limits)
+		})
+
+		t.Run("leaves fields excluded with WithSkip or WithOnly", func(t *testing.T) {
+			result, err := testfill.FillWithOptions(Server{}, testfill.WithSkip("Port"), testfill.WithOnly("Host", "Port", "Limits"))
+			require.NoError(t, err)
+
+			require.Equal(t, Server{Host: "localhost", Limits: []int{10, 100}}, result)
+		})
+
+		t.Run("applies to nested structs", func(t *testing.T) {
+			type Cluster struct {
+				Primary Server    `testfill:"fill"`
+				Nodes   []*Server `testfill:"unmarshal:[{}]"`
+				Backups []Server  `testfill:"fill:1"`
+			}
+
+			result, err := testfill.Fill(Cluster{})
+			require.NoError(t, err)
+
+			require.Equal(t, 9090, result.Primary.Port)
+			require.Equal(t, 9090, result.Backups[0].Port)
+		})
+	})
+
+	t.Run("WithFactoryIgnoreExtraReturns", func(t *testing.T) {
+		testfill.RegisterFactory("LookupPort", func(name string) (int, bool) {
+			ports := map[string]int{"http": 80, "https": 443}
+			port, ok := ports[name]
+			return port, ok
+		})
+		type Service struct {
+			Port int `testfill:"factory:LookupPort:https"`
+		}
+
+		t.Run("uses the first return value", func(t *testing.T) {
+			result, err := testfill.FillWithOptions(Service{}, testfill.WithFactoryIgnoreExtraReturns(true))
+			require.NoError(t, err)
+
+			require.Equal(t, 443, result.Port)
+		})
+
+		t.Run("still checks the first return type", func(t *testing.T) {
+			type Named struct {
+				Port string `testfill:"factory:LookupPort:http"`
+			}
+
+			_, err := testfill.FillWithOptions(Named{}, testfill.WithFactoryIgnoreExtraReturns(true))
+
+			require.EqualError(t, err, "testfill: failed to set field Port: factory function LookupPort returns int, but field expects string")
+		})
+
+		t.Run("fails on a non-nil trailing error", func(t *testing.T) {
+			testfill.RegisterFactory("ParsePort", func(s string) (int, error) {
+				if s == "bad" {
+					return 7, errors.New("boom")
+				}
+				return strconv.Atoi(s)
+			})
+			type Parsed struct {
+				Port int `testfill:"factory:ParsePort:bad"`
+				Next int `testfill:"factory:ParsePort:8080"`
+			}
+			ignore := testfill.WithFactoryIgnoreExtraReturns(true)
+
+			_, err := testfill.FillWithOptions(Parsed{}, ignore)
+			require.EqualError(t, err, "testfill: failed to set field Port: factory function ParsePort failed: boom")
+			require.ErrorIs(t, err, testfill.ErrFactory)
+
+			result, err := testfill.FillWithOptions(Parsed{Port: 1}, ignore)
+			require.NoError(t, err)
+			require.Equal(t, 8080, result.Next)
+		})
+
+		t.Run("requires a single return value by default", func(t *testing.T) {
+			_, err := testfill.Fill(Service{})
+
+			require.EqualError(t, err, "testfill: failed to set field Port: factory function LookupPort must return exactly one value")
+		})
+	})
+
+	t.Run("fill:tuple", func(t *testing.T) {
+		type Point struct {
+			X int
+			Y int
+		}
+
+		t.Run("sets exported fields in declaration order", func(t *testing.T) {
+			type Segment struct {
+				From  Point                  `testfill:"fill:tuple:1,2"`
+				To    *Point                 `testfill:"fill:tuple: 3, 4"`
+				Stamp struct{ At time.Time } `testfill:"fill:tuple:2024-01-02T00:00:00Z"`
+			}
+
+			result, err := testfill.Fill(Segment{})
+			require.NoError(t, err)
+
+			require.Equal(t, Point{X: 1, Y: 2}, result.From)
+			require.Equal(t, &Point{X: 3, Y: 4}, result.To)
+			require.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), result.Stamp.At)
+		})
+
+		t.Run("keeps fields already set", func(t *testing.T) {
+			type Shape struct {
+				Origin Point `testfill:"fill:tuple:1,2"`
+			}
+
+			result, err := testfill.Fill(Shape{Origin: Point{Y: 9}})
+			require.NoError(t, err)
+
+			require.Equal(t, Point{X: 1, Y: 9}, result.Origin)
+		})
+
+		t.Run("errors on count mismatch", func(t *testing.T) {
+			type Shape struct {
+				Origin Point `testfill:"fill:tuple:1,2,3"`
+			}
+
+			_, err := testfill.Fill(Shape{})
+
+			require.EqualError(t, err, "testfill: failed to set field Origin: tuple for testfill_test.Point expects 2 values, got 3")
+		})
+
+		t.Run("errors on invalid values with the field path", func(t *testing.T) {
+			type Shape struct {
+				Origin Point `testfill:"fill:tuple:1,abc"`
+			}
+
+			_, err := testfill.Fill(Shape{})
+
+			require.ErrorContains(t, err, "testfill: failed to set field Origin.Y:")
+		})
+
+		t.Run("errors on non-struct fields", func(t *testing.T) {
+			type Shape struct {
+				Sides int `testfill:"fill:tuple:4"`
+			}
+
+			_, err := testfill.Fill(Shape{})
+
+			require.EqualError(t, err, "testfill: failed to set field Sides: fill:tuple is not supported for int")
+		})
+	})
+
+	t.Run("rand:str", func(t *testing.T) {
+		type Token struct {
+			Code   string `testfill:"rand:str:16"`
+			Secret string `testfill:"rand:str:8:hex"`
+			Empty  string `testfill:"rand:str:0"`
+		}
+
+		t.Run("generates strings of the given length and charset", func(t *testing.T) {
+			result, err := testfill.Fill(Token{})
+			require.NoError(t, err)
+
+			require.Regexp(t, `^[a-zA-Z0-9]{16}$`, result.Code)
+			require.Regexp(t, `^[0-9a-f]{8}$`, result.Secret)
+			require.Empty(t, result.Empty)
+		})
+
+		t.Run("is reproducible with WithSeed", func(t *testing.T) {
+			first, err := testfill.FillWithOptions(Token{}, testfill.WithSeed(7))
+			require.NoError(t, err)
+			second, err := testfill.FillWithOptions(Token{}, testfill.WithSeed(7))
+			require.NoError(t, err)
+
+			require.Equal(t, first, second)
+		})
+
+		t.Run("errors on invalid lengths", func(t *testing.T) {
+			type Invalid struct {
+				Code string `testfill:"rand:str:-1"`
+			}
+
+			_, err := testfill.Fill(Invalid{})
+
+			require.EqualError(t, err, `testfill: failed to set field Code: invalid rand string length "-1"`)
+		})
+
+		t.Run("errors on unknown charsets", func(t *testing.T) {
+			type Invalid struct {
+				Code string `testfill:"rand:str:4:emoji"`
+			}
+
+			_, err := testfill.Fill(Invalid{})
+
+			require.EqualError(t, err, `testfill: failed to set field Code: unknown rand charset "emoji"`)
+		})
+
+		t.Run("errors on non-string fields", func(t *testing.T) {
+			type Invalid struct {
+				Code int `testfill:"rand:str:4"`
+			}
+
+			_, err := testfill.Fill(Invalid{})
+
+			require.EqualError(t, err, "testfill: failed to set field Code: unsupported type int for random values")
+		})
+	})
+
+	t.Run("deterministic dynamic values", func(t *testing.T) {
+		type Event struct {
+			ID     int       `testfill:"incr"`
+			Token  string    `testfill:"rand:str:8"`
+			Trace  string    `testfill:"uuid"`
+			At     time.Time `testfill:"now"`
+			Local  time.Time `testfill:"tz:Europe/Paris:now"`
+			Future time.Time `testfill:"unix:0"`
+		}
+		fixed := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+		fillEvent := func() Event {
+			result, err := testfill.FillWithOptions(Event{},
+				testfill.WithNow(func() time.Time { return fixed }),
+				testfill.WithRandSource(rand.NewSource(1)),
+				testfill.WithCounters(testfill.NewCounters()),
+			)
+			require.NoError(t, err)
+			return result
+		}
+
+		t.Run("reads now from WithNow", func(t *testing.T) {
+			result := fillEvent()
+
+			require.Equal(t, fixed, result.At)
+			require.True(t, fixed.Equal(result.Local))
+			require.Equal(t, "Europe/Paris", result.Local.Location().String())
+		})
+
+		t.Run("produces the same values on every call", func(t *testing.T) {
+			require.Equal(t, fillEvent(), fillEvent())
+		})
+
+		t.Run("keeps WithCounters sequences apart from the package counters", func(t *testing.T) {
+			counters := testfill.NewCounters()
+
+			first, err := testfill.FillWithOptions(Event{}, testfill.WithCounters(counters))
+			require.NoError(t, err)
+			second, err := testfill.FillWithOptions(Event{}, testfill.WithCounters(counters))
+			require.NoError(t, err)
+			require.Equal(t, 1, first.ID)
+			require.Equal(t, 2, second.ID)
+
+			counters.Reset()
+			third, err := testfill.FillWithOptions(Event{}, testfill.WithCounters(counters))
+			require.NoError(t, err)
+			require.Equal(t, 1, third.ID)
+		})
+	})
+
+	t.Run("WithConvertibleFactoryReturns", func(t *testing.T) {
+		type Centimeters int
+		testfill.RegisterFactory("Height", func() Centimeters { return 180 })
+		type Person struct {
+			Height int `testfill:"factory:Height"`
+		}
+
+		t.Run("converts convertible results", func(t *testing.T) {
+			result, err := testfill.FillWithOptions(Person{}, testfill.WithConvertibleFactoryReturns(true))
+			require.NoError(t, err)
+
+			require.Equal(t, 180, result.Height)
+		})
+
+		t.Run("does not convert numbers to strings", func(t *testing.T) {
+			type Label struct {
+				Height string `testfill:"factory:Height"`
+			}
+
+			_, err := testfill.FillWithOptions(Label{}, testfill.WithConvertibleFactoryReturns(true))
+
+			require.EqualError(t, err, "testfill: failed to set field Height: factory function Height returns testfill_test.Centimeters, but field expects string")
+		})
+
+		t.Run("requires assignable results by default", func(t *testing.T) {
+			_, err := testfill.Fill(Person{})
+
+			require.EqualError(t, err, "testfill: failed to set field Height: factory function Height returns testfill_test.Centimeters, but field expects int")
+		})
+	})
+
+	t.Run("blank", func(t *testing.T) {
+		type Profile struct {
+			Name   string `testfill:"Jane"`
+			Avatar string `testfill:"blank" testfill_full:"avatar.png"`
+			Home   Bar    `testfill:"blank"`
+		}
+		type Account struct {
+			Profile Profile `testfill:"fill"`
+			Bar     `testfill:"blank"`
+		}
+
+		t.Run("keeps the field zero inside filled structs", func(t *testing.T) {
+			result, err := testfill.FillWithOptions(Account{}, testfill.WithAutoFillEmbedded(true))
+			require.NoError(t, err)
+
+			require.Equal(t, Account{Profile: Profile{Name: "Jane"}}, result)
+		})
+
+		t.Run("is overridden by variant tags", func(t *testing.T) {
+			result, err := testfill.FillWithVariant(Profile{}, "full")
+			require.NoError(t, err)
+
+			require.Equal(t, "avatar.png", result.Avatar)
+		})
+
+		t.Run("plan reports the skipped field", func(t *testing.T) {
+			actions, err := testfill.Plan(Account{})
+			require.NoError(t, err)
+
+			require.Contains(t, actions, testfill.FillAction{
+				Path:    "Profile.Avatar",
+				Tag:     "blank",
+				Skipped: testfill.SkipBlank,
+			})
+		})
+	})
+
+	t.Run("quoted map keys and values", func(t *testing.T) {
+		t.Run("keep delimiters inside quotes", func(t *testing.T) {
+			type Quoted struct {
+				Keys   map[string]int            `testfill:"\"a,b\":1,\"c:d\":2,plain:3"`
+				Values map[string]string         `testfill:"greeting:\"hello, world\",time:\"12:30\""`
+				Nested map[string]map[string]int `testfill:"x:\"a=b\"=1;c=2"`
+				Teams  map[string]Bar            `testfill:"\"red, blue\":fill"`
+			}
+
+			result, err := testfill.Fill(Quoted{})
+			require.NoError(t, err)
+
+			require.Equal(t, map[string]int{"a,b": 1, "c:d": 2, "plain": 3}, result.Keys)
+			require.Equal(t, map[string]string{"greeting": "hello, world", "time": "12:30"}, result.Values)
+			require.Equal(t, map[string]map[string]int{"x": {"a=b": 1, "c": 2}}, result.Nested)
+			require.Equal(t, map[string]Bar{"red, blue": {Integer: 42, String: "Olivie Smith"}}, result.Teams)
+		})
+
+		t.Run("support escaped quotes", func(t *testing.T) {
+			type Escaped struct {
+				Value map[string]string `testfill:"\"say \\\"hi\\\"\":yes"`
+			}
+
+			result, err := testfill.Fill(Escaped{})
+			require.NoError(t, err)
+
+			require.Equal(t, map[string]string{`say "hi"`: "yes"}, result.Value)
+		})
+
+		t.Run("still reject unquoted extra delimiters", func(t *testing.T) {
+			type Invalid struct {
+				Value map[string]string `testfill:"time:12:30"`
+			}
+
+			_, err := testfill.Fill(Invalid{})
+
+			require.EqualError(t, err, "testfill: failed to set field Value: invalid map format: time:12:30")
+		})
+	})
+
+	t.Run("literal tags on interface{} fields", func(t *testing.T) {
+		t.Run("unmarshal JSON values and keep other text as strings", func(t *testing.T) {
+			type Payload struct {
+				Number  interface{} `testfill:"42"`
+				Flag    any         `testfill:"true"`
+				List    any         `testfill:"[1,\"a\"]"`
+				Text    any         `testfill:"hello"`
+				Quoted  any         `testfill:"\"42\""`
+				Decoded any         `testfill:"unmarshal:42"`
+			}
+
+			result, err := testfill.Fill(Payload{})
+			require.NoError(t, err)
+
+			require.Equal(t, Payload{
+				Number:  float64(42),
+				Flag:    true,
+				List:    []interface{}{float64(1), "a"},
+				Text:    "hello",
+				Quoted:  "42",
+				Decoded: float64(42),
+			}, result)
+		})
+
+		t.Run("error on interfaces with methods", func(t *testing.T) {
+			type Invalid struct {
+				Value fmt.Stringer `testfill:"hello"`
+			}
+
+			_, err := testfill.Fill(Invalid{})
+
+			require.EqualError(t, err, "testfill: failed to set field Value: unsupported field type interface")
+		})
+
+		t.Run("error on directives other than literals", func(t *testing.T) {
+			type Invalid struct {
+				Value any `testfill:"seq"`
+			}
+
+			_, err := testfill.Fill(Invalid{})
+
+			require.EqualError(t, err, "testfill: failed to set field Value: unsupported field type interface")
+		})
+	})
+
+	t.Run("WithTimeLayouts", func(t *testing.T) {
+		layouts := testfill.WithTimeLayouts(time.RFC3339, "2006-01-02", "2006-01-02 15:04:05")
+
+		t.Run("tries each layout in order", func(t *testing.T) {
+			type Booking struct {
+				Created time.Time `testfill:"2024-01-02T10:00:00Z"`
+				Day     time.Time `testfill:"2024-03-04"`
+				Start   time.Time `testfill:"2024-03-04 09:30:00"`
+				Now     time.Time `testfill:"now"`
+			}
+
+			result, err := testfill.FillWithOptions(Booking{}, layouts)
+			require.NoError(t, err)
+
+			require.Equal(t, time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC), result.Created)
+			require.Equal(t, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), result.Day)
+			require.Equal(t, time.Date(2024, 3, 4, 9, 30, 0, 0, time.UTC), result.Start)
+			require.False(t, result.Now.IsZero())
+		})
+
+		t.Run("reports the error of the last layout", func(t *testing.T) {
+			type Booking struct {
+				Day time.Time `testfill:"04/03/2024"`
+			}
+
+			_, err := testfill.FillWithOptions(Booking{}, layouts)
+
+			require.EqualError(t, err, `testfill: failed to set field Day: parsing time "04/03/2024" as "2006-01-02 15:04:05": cannot parse "04/03/2024" as "2006"`)
+		})
+
+		t.Run("accepts only RFC3339 by default", func(t *testing.T) {
+			type Booking struct {
+				Day time.Time `testfill:"2024-03-04"`
+			}
+
+			_, err := testfill.Fill(Booking{})
+
+			require.ErrorContains(t, err, `parsing time "2024-03-04" as "2006-01-02T15:04:05Z07:00"`)
+		})
+	})
+
+	t.Run("dates", func(t *testing.T) {
+		t.Run("generates count times stepped from the start", func(t *testing.T) {
+			type Series struct {
+				Days   []time.Time  `testfill:"dates:2023-01-01:24h:3"`
+				Hours  []time.Time  `testfill:"dates:2023-01-01T22:00:00Z:1h30m:2"`
+				Empty  []time.Time  `testfill:"dates:2023-01-01:24h:0"`
+				Backup [2]time.Time `testfill:"dates:2023-01-01:-24h:2"`
+			}
+
+			result, err := testfill.Fill(Series{})
+			require.NoError(t, err)
+
+			day := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+			require.Equal(t, []time.Time{day, day.AddDate(0, 0, 1), day.AddDate(0, 0, 2)}, result.Days)
+			require.Equal(t, []time.Time{
+				time.Date(2023, 1, 1, 22, 0, 0, 0, time.UTC),
+				time.Date(2023, 1, 1, 23, 30, 0, 0, time.UTC),
+			}, result.Hours)
+			require.Empty(t, result.Empty)
+			require.Equal(t, [2]time.Time{day, day.AddDate(0, 0, -1)}, result.Backup)
+		})
+
+		t.Run("parses the tag", func(t *testing.T) {
+			directive, err := testfill.ParseTag("dates:2023-01-01T00:00:00Z:24h:5")
+			require.NoError(t, err)
+
+			require.Equal(t, testfill.DirectiveDates, directive.Kind)
+			require.Equal(t, "2023-01-01T00:00:00Z", directive.Value)
+			require.Equal(t, []string{"24h", "5"}, directive.Args)
+		})
+
+		t.Run("errors on invalid parts", func(t *testing.T) {
+			type MissingParts struct {
+				Days []time.Time `testfill:"dates:24h:5"`
+			}
+			type InvalidStart struct {
+				Days []time.Time `testfill:"dates:yesterday:24h:5"`
+			}
+			type InvalidStep struct {
+				Days []time.Time `testfill:"dates:2023-01-01:day:5"`
+			}
+			type InvalidCount struct {
+				Days []time.Time `testfill:"dates:2023-01-01:24h:x"`
+			}
+
+			_, err := testfill.Fill(MissingParts{})
+			require.EqualError(t, err, "testfill: failed to set field Days: invalid dates format: dates:24h:5 (expected format: dates:start:step:count)")
+			_, err = testfill.Fill(InvalidStart{})
+			require.EqualError(t, err, `testfill: failed to set field Days: invalid dates start "yesterday"`)
+			_, err = testfill.Fill(InvalidStep{})
+			require.EqualError(t, err, `testfill: failed to set field Days: invalid dates step "day"`)
+			_, err = testfill.Fill(InvalidCount{})
+			require.EqualError(t, err, `testfill: failed to set field Days: invalid dates count "x"`)
+		})
+
+		t.Run("errors on non-time slices", func(t *testing.T) {
+			type Invalid struct {
+				Days []string `testfill:"dates:2023-01-01:24h:2"`
+			}
+
+			_, err := testfill.Fill(Invalid{})
+
+			require.EqualError(t, err, "testfill: failed to set field Days: dates is not supported for string elements")
+		})
+	})
+
+	t.Run("sql.Scanner", func(t *testing.T) {
+		t.Run("scans the tag into the field", func(t *testing.T) {
+			type Row struct {
+				Name    sql.NullString  `testfill:"hello"`
+				Age     sql.NullInt64   `testfill:"42"`
+				Active  sql.NullBool    `testfill:"true"`
+				Balance sql.NullFloat64 `testfill:"9.5"`
+				Manual  sql.NullString  `testfill:"ignored"`
+			}
+
+			result, err := testfill.Fill(Row{Manual: sql.NullString{String: "kept", Valid: true}})
+			require.NoError(t, err)
+
+			require.Equal(t, sql.NullString{String: "hello", Valid: true}, result.Name)
+			require.Equal(t, sql.NullInt64{Int64: 42, Valid: true}, result.Age)
+			require.Equal(t, sql.NullBool{Bool: true, Valid: true}, result.Active)
+			require.Equal(t, sql.NullFloat64{Float64: 9.5, Valid: true}, result.Balance)
+			require.Equal(t, "kept", result.Manual.String)
+		})
+
+		t.Run("errors when the scan fails", func(t *testing.T) {
+			type Row struct {
+				Age sql.NullInt64 `testfill:"forty"`
+			}
+
+			_, err := testfill.Fill(Row{})
+
+			require.ErrorContains(t, err, `testfill: failed to set field Age: cannot convert "forty" to sql.NullInt64:`)
+		})
+	})
+
+	t.Run("nil and null pointer keywords", func(t *testing.T) {
+		t.Run("leave pointers nil", func(t *testing.T) {
+			type Optional struct {
+				Count   *int            `testfill:"nil"`
+				Name    *string         `testfill:"null"`
+				Tags    *[]string       `testfill:"nil"`
+				Owner   *Bar            `testfill:"null"`
+				Literal *string         `testfill:"unmarshal:\"nil\""`
+				Admin   *Bar            `testfill:"nil" testfill_admin:"fill"`
+				Lookup  *map[string]int `testfill:"null"`
+			}
+
+			result, err := testfill.Fill(Optional{})
+			require.NoError(t, err)
+
+			require.Equal(t, Optional{Literal: result.Literal}, result)
+			require.Equal(t, "nil", *result.Literal)
+
+			admin, err := testfill.FillWithVariant(Optional{}, "admin")
+			require.NoError(t, err)
+			require.Equal(t, &Bar{Integer: 42, String: "Olivie Smith"}, admin.Admin)
+		})
+
+		t.Run("keep non-pointer fields literal", func(t *testing.T) {
+			type Words struct {
+				Value string `testfill:"nil"`
+			}
+
+			result, err := testfill.Fill(Words{})
+			require.NoError(t, err)
+
+			require.Equal(t, "nil", result.Value)
+		})
+	})
+
+	t.Run("month and weekday names", func(t *testing.T) {
+		t.Run("accept names in any case and numbers", func(t *testing.T) {
+			type Schedule struct {
+				Month    time.Month         `testfill:"January"`
+				Day      time.Weekday       `testfill:"monday"`
+				Numeric  time.Month         `testfill:"12"`
+				Weekend  []time.Weekday     `testfill:"Saturday,Sunday"`
+				Holidays map[time.Month]int `testfill:"December:25,july:4"`
+				Factory  time.Weekday       `testfill:"factory:Weekday:Friday"`
+			}
+			testfill.RegisterFactory("Weekday", func(d time.Weekday) time.Weekday { return d })
+
+			result, err := testfill.Fill(Schedule{})
+			require.NoError(t, err)
+
+			require.Equal(t, time.January, result.Month)
+			require.Equal(t, time.Monday, result.Day)
+			require.Equal(t, time.December, result.Numeric)
+			require.Equal(t, []time.Weekday{time.Saturday, time.Sunday}, result.Weekend)
+			require.Equal(t, map[time.Month]int{time.December: 25, time.July: 4}, result.Holidays)
+			require.Equal(t, time.Friday, result.Factory)
+		})
+
+		t.Run("errors on unknown names", func(t *testing.T) {
+			type Schedule struct {
+				Month time.Month `testfill:"Smarch"`
+			}
+
+			_, err := testfill.Fill(Schedule{})
+
+			require.ErrorContains(t, err, `testfill: failed to set field Month: cannot convert "Smarch" to int`)
+		})
+	})
+
+	t.Run("error kinds", func(t *testing.T) {
+		t.Run("match conversion errors", func(t *testing.T) {
+			type Invalid struct {
+				Count int `testfill:"many"`
+			}
+
+			_, err := testfill.Fill(Invalid{})
+
+			require.ErrorIs(t, err, testfill.ErrConversion)
+			require.NotErrorIs(t, err, testfill.ErrFactory)
+			require.EqualError(t, err, `testfill: failed to set field Count: cannot convert "many" to int: strconv.ParseInt: parsing "many": invalid syntax`)
+			var fieldErr *testfill.FieldError
+			require.ErrorAs(t, err, &fieldErr)
+			var numErr *strconv.NumError
+			require.ErrorAs(t, err, &numErr)
+		})
+
+		t.Run("match factory errors", func(t *testing.T) {
+			type Invalid struct {
+				Value string `testfill:"factory:Missing"`
+			}
+
+			_, err := testfill.Fill(Invalid{})
+
+			require.ErrorIs(t, err, testfill.ErrFactory)
+			require.EqualError(t, err, "testfill: failed to set field Value: factory function Missing not found")
+		})
+
+		t.Run("match unsupported types", func(t *testing.T) {
+			type Invalid struct {
+				Updates chan int `testfill:"1"`
+			}
+
+			_, err := testfill.Fill(Invalid{})
+
+			require.ErrorIs(t, err, testfill.ErrUnsupported)
+			require.EqualError(t, err, "testfill: failed to set field Updates: unsupported field type chan")
+		})
+	})
+
+	t.Run("WithMaxSliceCount", func(t *testing.T) {
+		t.Run("allows counts up to the maximum", func(t *testing.T) {
+			type Page struct {
+				Items []Bar       `testfill:"fill:3"`
+				Codes []int       `testfill:"repeat:3:7"`
+				Days  []time.Time `testfill:"dates:2023-01-01:24h:3"`
+				Pages []int       `testfill:"range:1..3"`
+			}
+
+			result, err := testfill.FillWithOptions(Page{}, testfill.WithMaxSliceCount(3))
+			require.NoError(t, err)
+
+			require.Len(t, result.Items, 3)
+			require.Len(t, result.Codes, 3)
+			require.Len(t, result.Days, 3)
+			require.Len(t, result.Pages, 3)
+		})
+
+		t.Run("rejects larger counts", func(t *testing.T) {
+			type Items struct {
+				Value []Bar `testfill:"fill:1000000"`
+			}
+			type Codes struct {
+				Value []int `testfill:"repeat:1000000:7"`
+			}
+			type Days struct {
+				Value []time.Time `testfill:"dates:2023-01-01:24h:1000000"`
+			}
+			type Pages struct {
+				Value []int `testfill:"range:1..1000000"`
+			}
+			limit := testfill.WithMaxSliceCount(100)
+
+			_, err := testfill.FillWithOptions(Items{}, limit)
+			require.EqualError(t, err, "testfill: failed to set field Value: slice count 1000000 exceeds the maximum of 100")
+			_, err = testfill.FillWithOptions(Codes{}, limit)
+			require.EqualError(t, err, "testfill: failed to set field Value: slice count 1000000 exceeds the maximum of 100")
+			_, err = testfill.FillWithOptions(Days{}, limit)
+			require.EqualError(t, err, "testfill: failed to set field Value: slice count 1000000 exceeds the maximum of 100")
+			_, err = testfill.FillWithOptions(Pages{}, limit)
+			require.EqualError(t, err, "testfill: failed to set field Value: slice count 1000000 exceeds the maximum of 100")
+		})
+	})
+
+	t.Run("Builder", func(t *testing.T) {
+		type Member struct {
+			Name  string `testfill:"Jane" testfill_admin:"Root"`
+			Role  string `testfill:"user" testfill_admin:"admin"`
+			Score int    `testfill:"10"`
+			Tags  []int  `testfill:"repeat:3:1"`
+		}
+
+		t.Run("keeps fields set in code and fills the rest", func(t *testing.T) {
+			result, err := testfill.NewBuilder[Member]().
+				With(func(m *Member) { m.Role = "owner" }).
+				With(func(m *Member) { m.Score = 99 }).
+				Fill()
+			require.NoError(t, err)
+
+			require.Equal(t, Member{Name: "Jane", Role: "owner", Score: 99, Tags: []int{1, 1, 1}}, result)
+		})
+
+		t.Run("applies variants and options", func(t *testing.T) {
+			builder := testfill.NewBuilder[Member]().
+				With(func(m *Member) { m.Name = "Alice" }).
+				WithVariants("admin").
+				WithOptions(testfill.WithSkip("Tags"))
+
+			result := builder.MustFill()
+
+			require.Equal(t, Member{Name: "Alice", Role: "admin", Score: 10}, result)
+		})
+
+		t.Run("builds a separate value on every call", func(t *testing.T) {
+			builder := testfill.NewBuilder[Member]()
+
+			first := builder.MustFill()
+			first.Tags[0] = 5
+			second := builder.MustFill()
+
+			require.Equal(t, []int{1, 1, 1}, second.Tags)
+		})
+
+		t.Run("panics on error with MustFill", func(t *testing.T) {
+			type Invalid struct {
+				Count int `testfill:"many"`
+			}
+
+			require.Panics(t, func() { testfill.NewBuilder[Invalid]().MustFill() })
+		})
+	})
+
+	t.Run("hex", func(t *testing.T) {
+		t.Run("decodes into byte arrays and slices", func(t *testing.T) {
+			type Vector struct {
+				Key   [4]byte  `testfill:"hex:00112233"`
+				Nonce []byte   `testfill:"hex:DEADbeef"`
+				Empty []byte   `testfill:"hex:"`
+				Ptr   *[2]byte `testfill:"hex:ff00"`
+			}
+
+			result, err := testfill.Fill(Vector{})
+			require.NoError(t, err)
+
+			require.Equal(t, [4]byte{0x00, 0x11, 0x22, 0x33}, result.Key)
+			require.Equal(t, []byte{0xde, 0xad, 0xbe, 0xef}, result.Nonce)
+			require.Empty(t, result.Empty)
+			require.Equal(t, &[2]byte{0xff, 0x00}, result.Ptr)
+		})
+
+		t.Run("errors when the length does not match the array", func(t *testing.T) {
+			type Vector struct {
+				Key [4]byte `testfill:"hex:001122"`
+			}
+
+			_, err := testfill.Fill(Vector{})
+
+			require.EqualError(t, err, "testfill: failed to set field Key: array of length 4 cannot be filled with 3 values")
+		})
+
+		t.Run("errors on invalid hex", func(t *testing.T) {
+			type Vector struct {
+				Key []byte `testfill:"hex:xyz"`
+			}
+
+			_, err := testfill.Fill(Vector{})
+
+			require.EqualError(t, err, `testfill: failed to set field Key: invalid hex value "xyz": encoding/hex: invalid byte: U+0078 'x'`)
+			require.ErrorIs(t, err, testfill.ErrConversion)
+		})
+
+		t.Run("errors on non-byte elements", func(t *testing.T) {
+			type Vector struct {
+				Key []int `testfill:"hex:00"`
+			}
+
+			_, err := testfill.Fill(Vector{})
+
+			require.EqualError(t, err, "testfill: failed to set field Key: hex is not supported for int elements")
+		})
+	})
+
+	t.Run("unmarshal+fill", func(t *testing.T) {
+		type Address struct {
+			Street string `json:"street" testfill:"123 Main St"`
+			City   string `json:"city" testfill:"New York" testfill_eu:"Paris"`
+			Zip    string `json:"zip" testfill:"10001"`
+		}
+
+		t.Run("fills the fields the JSON leaves zero", func(t *testing.T) {
+			type Customer struct {
+				Home    Address  `testfill:"unmarshal+fill:{\"city\":\"Boston\"}"`
+				Billing *Address `testfill:"unmarshal+fill:{\"zip\":\"02101\"}"`
+				Plain   Address  `testfill:"unmarshal:{\"city\":\"Boston\"}"`
+				None    *Address `testfill:"unmarshal+fill:null"`
+			}
+
+			result, err := testfill.Fill(Customer{})
+			require.NoError(t, err)
+
+			require.Equal(t, Address{Street: "123 Main St", City: "Boston", Zip: "10001"}, result.Home)
+			require.Equal(t, &Address{Street: "123 Main St", City: "New York", Zip: "02101"}, result.Billing)
+			require.Equal(t, Address{City: "Boston"}, result.Plain)
+			require.Nil(t, result.None)
+		})
+
+		t.Run("applies variants to the gaps", func(t *testing.T) {
+			type Customer struct {
+				Home Address `testfill:"unmarshal+fill:{\"zip\":\"75001\"}"`
+			}
+
+			result, err := testfill.FillWithVariant(Customer{}, "eu")
+			require.NoError(t, err)
+
+			require.Equal(t, Address{Street: "123 Main St", City: "Paris", Zip: "75001"}, result.Home)
+		})
+
+		t.Run("keeps caller values instead of unmarshaling", func(t *testing.T) {
+			type Customer struct {
+				Home Address `testfill:"unmarshal+fill:{\"city\":\"Boston\"}"`
+			}
+
+			result, err := testfill.Fill(Customer{Home: Address{Zip: "99999"}})
+			require.NoError(t, err)
+
+			require.Equal(t, Address{Street: "123 Main St", City: "New York", Zip: "99999"}, result.Home)
+		})
+
+		t.Run("errors on invalid JSON", func(t *testing.T) {
+			type Customer struct {
+				Home Address `testfill:"unmarshal+fill:{city}"`
+			}
+
+			_, err := testfill.Fill(Customer{})
+
+			require.ErrorContains(t, err, "testfill: failed to set field Home: failed to unmarshal JSON:")
+		})
+	})
+
+	t.Run("- ignores the field", func(t *testing.T) {
+		type Secret struct {
+			Token string `testfill:"-" testfill_admin:"root-token"`
+			Label string `testfill:"secret"`
+		}
+		type Vault struct {
+			Main    Secret   `testfill:"fill"`
+			Secrets []Secret `testfill:"fill:2"`
+			Hidden  *Secret  `testfill:"-"`
+			Secret  `testfill:"-"`
+		}
+		type Bank struct {
+			Vault Vault `testfill:"fill"`
+		}
+
+		t.Run("skips it inside deeply nested fills, whatever the variant", func(t *testing.T) {
+			result, err := testfill.FillWithOptions(Bank{}, testfill.WithAutoFillEmbedded(true))
+			require.NoError(t, err)
+
+			require.Equal(t, Secret{Label: "secret"}, result.Vault.Main)
+			require.Equal(t, Secret{}, result.Vault.Secret)
+			require.Equal(t, []Secret{{Label: "secret"}, {Label: "secret"}}, result.Vault.Secrets)
+			require.Nil(t, result.Vault.Hidden)
+
+			admin, err := testfill.FillWithVariant(Bank{}, "admin")
+			require.NoError(t, err)
+			require.Empty(t, admin.Vault.Main.Token)
+		})
+
+		t.Run("keeps caller values", func(t *testing.T) {
+			result, err := testfill.Fill(Secret{Token: "mine"})
+			require.NoError(t, err)
+
+			require.Equal(t, Secret{Token: "mine", Label: "secret"}, result)
+		})
+
+		t.Run("plan reports the ignored field", func(t *testing.T) {
+			actions, err := testfill.Plan(Bank{})
+			require.NoError(t, err)
+
+			require.Contains(t, actions, testfill.FillAction{
+				Path:    "Vault.Secrets[1].Token",
+				Tag:     "-",
+				Skipped: testfill.SkipIgnored,
+			})
+		})
+	})
+
+	t.Run("fill:count=Field", func(t *testing.T) {
+		t.Run("sizes the slice from an integer sibling", func(t *testing.T) {
+			type Order struct {
+				Items     []Bar  `testfill:"fill:count=Count"`
+				Count     int    `testfill:"2"`
+				Pointers  []*Bar `testfill:"fill:count=Quantity"`
+				Quantity  uint8
+				Untouched []Bar `testfill:"fill:count=Count"`
+			}
+
+			result, err := testfill.Fill(Order{Quantity: 3, Untouched: []Bar{{}}})
+			require.NoError(t, err)
+
+			require.Len(t, result.Items, 2)
+			require.Equal(t, Bar{Integer: 42, String: "Olivie Smith"}, result.Items[1])
+			require.Len(t, result.Pointers, 3)
+			require.Equal(t, []Bar{{}}, result.Untouched)
+		})
+
+		t.Run("errors on missing fields", func(t *testing.T) {
+			type Order struct {
+				Items []Bar `testfill:"fill:count=Missing"`
+			}
+
+			_, err := testfill.Fill(Order{})
+
+			require.EqualError(t, err, "testfill: failed to set field Items: unknown field Missing referenced")
+		})
+
+		t.Run("errors on non-integer fields", func(t *testing.T) {
+			type Order struct {
+				Items []Bar  `testfill:"fill:count=Count"`
+				Count string `testfill:"two"`
+			}
+
+			_, err := testfill.Fill(Order{})
+
+			require.EqualError(t, err, "testfill: failed to set field Items: count field Count is string, not an integer")
+		})
+
+		t.Run("errors on negative counts", func(t *testing.T) {
+			type Order struct {
+				Items []Bar `testfill:"fill:count=Count"`
+				Count int
+			}
+
+			_, err := testfill.Fill(Order{Count: -1})
+
+			require.EqualError(t, err, "testfill: failed to set field Items: invalid slice count format: fill:-1")
+		})
+	})
+
+	t.Run("types defined on time.Time", func(t *testing.T) {
+		type Timestamp time.Time
+
+		t.Run("parse like time.Time", func(t *testing.T) {
+			type Event struct {
+				At      Timestamp            `testfill:"2024-01-02T03:04:05Z"`
+				Local   Timestamp            `testfill:"tz:Europe/Paris:2024-01-02 10:00"`
+				Ptr     *Timestamp           `testfill:"unix:0"`
+				History []Timestamp          `testfill:"2024-01-01T00:00:00Z,2024-06-01T00:00:00Z"`
+				ByName  map[string]Timestamp `testfill:"start:\"2024-01-01T00:00:00Z\""`
+			}
+
+			result, err := testfill.Fill(Event{})
+			require.NoError(t, err)
+
+			jan1 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			require.Equal(t, Timestamp(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)), result.At)
+			require.Equal(t, "2024-01-02T10:00:00+01:00", time.Time(result.Local).Format(time.RFC3339))
+			require.Equal(t, Timestamp(time.Unix(0, 0).UTC()), *result.Ptr)
+			require.Equal(t, []Timestamp{Timestamp(jan1), Timestamp(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))}, result.History)
+			require.Equal(t, map[string]Timestamp{"start": Timestamp(jan1)}, result.ByName)
+		})
+
+		t.Run("errors on invalid values", func(t *testing.T) {
+			type Event struct {
+				At Timestamp `testfill:"yesterday"`
+			}
+
+			_, err := testfill.Fill(Event{})
+
+			require.ErrorContains(t, err, `testfill: failed to set field At: parsing time "yesterday"`)
+			require.ErrorIs(t, err, testfill.ErrConversion)
+		})
+	})
+
+	t.Run("WithWarnUnexportedTags", func(t *testing.T) {
+		type Account struct {
+			Name   string `testfill:"Jane"`
+			secret string `testfill:"hunter2"`
+		}
+
+		t.Run("errors on tagged unexported fields", func(t *testing.T) {
+			_, err := testfill.FillWithOptions(Account{}, testfill.WithWarnUnexportedTags(true))
+
+			require.EqualError(t, err, "testfill: failed to set field secret: field is unexported and cannot be filled")
+		})
+
+		t.Run("ignores untagged unexported fields", func(t *testing.T) {
+			type Counter struct {
+				Start int `testfill:"1"`
+				count int
+			}
+
+			result, err := testfill.FillWithOptions(Counter{}, testfill.WithWarnUnexportedTags(true))
+			require.NoError(t, err)
+
+			require.Equal(t, Counter{Start: 1}, result)
+		})
+
+		t.Run("ignores unexported fields tagged -", func(t *testing.T) {
+			type Vault struct {
+				Name   string `testfill:"Jane"`
+				secret string `testfill:"-" testfill_admin:"root"`
+			}
+
+			result, err := testfill.FillWithOptions(Vault{}, testfill.WithWarnUnexportedTags(true))
+			require.NoError(t, err)
+			require.Equal(t, "Jane", result.Name)
+
+			_, err = testfill.NewBuilder[Vault]().WithOptions(testfill.WithWarnUnexportedTags(true)).WithVariants("admin").Fill()
+			require.NoError(t, err)
+		})
+
+		t.Run("skips them silently by default", func(t *testing.T) {
+			result, err := testfill.Fill(Account{})
+			require.NoError(t, err)
+
+			require.Equal(t, "Jane", result.Name)
+			require.Empty(t, result.secret)
+		})
+	})
+
+	t.Run("ZeroThenFill", func(t *testing.T) {
+		type Fixture struct {
+			Name     string `testfill:"Jane"`
+			Bar      Bar    `testfill:"fill"`
+			Tags     []int  `testfill:"1,2"`
+			ID       int    `testfill:"required"`
+			Internal string `testfill:"-"`
+			Notes    string
+		}
+
+		t.Run("resets tagged fields to their tag values", func(t *testing.T) {
+			input := Fixture{
+				Name:     "Bob",
+				Bar:      Bar{Integer: 7},
+				Tags:     []int{9},
+				ID:       5,
+				Internal: "kept",
+				Notes:    "kept",
+			}
+
+			result, err := testfill.ZeroThenFill(input)
+			require.NoError(t, err)
+
+			require.Equal(t, Fixture{
+				Name:     "Jane",
+				Bar:      Bar{Integer: 42, String: "Olivie Smith"},
+				Tags:     []int{1, 2},
+				ID:       5,
+				Internal: "kept",
+				Notes:    "kept",
+			}, result)
+			require.Equal(t, "Bob", input.Name)
+		})
+
+		t.Run("keeps untagged fields of nested fill structs", func(t *testing.T) {
+			type Address struct {
+				City string `testfill:"Paris"`
+				Note string
+			}
+			type Customer struct {
+				Home Address  `testfill:"fill"`
+				Work *Address `testfill:"fill"`
+			}
+			work := &Address{City: "Rome", Note: "3rd floor"}
+			input := Customer{Home: Address{City: "Oslo", Note: "ring twice"}, Work: work}
+
+			result, err := testfill.ZeroThenFill(input)
+			require.NoError(t, err)
+
+			require.Equal(t, Address{City: "Paris", Note: "ring twice"}, result.Home)
+			require.Equal(t, &Address{City: "Paris", Note: "3rd floor"}, result.Work)
+			require.Equal(t, &Address{City: "Rome", Note: "3rd floor"}, work)
+		})
+
+		t.Run("behaves like Fill for other inputs", func(t *testing.T) {
+			_, err := testfill.ZeroThenFill(42)
+
+			require.EqualError(t, err, "testfill: expected struct, got int")
+		})
+	})
+
+	t.Run("arrays inside maps and slices", func(t *testing.T) {
+		t.Run("fills array elements and values", func(t *testing.T) {
+			type ArrayContainers struct {
+				Scores  map[string][3]int `testfill:"a:1;2;3,b:4;5;6"`
+				Pairs   [][2]string       `testfill:"x;y,z;w"`
+				Grid    [2][2]int         `testfill:"1;2,3;4"`
+				Repeats [][2]int          `testfill:"repeat:2:7;8"`
+			}
+
+			result, err := testfill.Fill(ArrayContainers{})
+			require.NoError(t, err)
+
+			require.Equal(t, map[string][3]int{"a": {1, 2, 3}, "b": {4, 5, 6}}, result.Scores)
+			require.Equal(t, [][2]string{{"x", "y"}, {"z", "w"}}, result.Pairs)
+			require.Equal(t, [2][2]int{{1, 2}, {3, 4}}, result.Grid)
+			require.Equal(t, [][2]int{{7, 8}, {7, 8}}, result.Repeats)
+		})
+
+		t.Run("reports the element with a length mismatch", func(t *testing.T) {
+			type ArrayContainers struct {
+				Pairs [][2]string `testfill:"x;y,z;w;v"`
+			}
+
+			_, err := testfill.Fill(ArrayContainers{})
+
+			require.ErrorContains(t, err, "failed to set field Pairs[1]: array of length 2 cannot be filled with 3 values")
+			var fieldErr *testfill.FieldError
+			require.ErrorAs(t, err, &fieldErr)
+			require.Equal(t, []string{"Pairs"}, fieldErr.Path)
+			require.Equal(t, "[1]", fieldErr.Field)
+		})
+
+		t.Run("reports the map key with a length mismatch", func(t *testing.T) {
+			type ArrayContainers struct {
+				Scores map[string][3]int `testfill:"a:1;2"`
+			}
+
+			_, err := testfill.Fill(ArrayContainers{})
+
+			require.ErrorContains(t, err, "failed to set field Scores[a]: array of length 3 cannot be filled with 2 values")
+		})
+	})
+
+	t.Run("ref directive", func(t *testing.T) {
+		tenant := &Bar{Integer: 7, String: "acme"}
+		testfill.RegisterObject("defaultTenant", tenant)
+		defer testfill.RegisterObject("defaultTenant", nil)
+
+		t.Run("shares the registered object", func(t *testing.T) {
+			type Account struct {
+				Tenant *Bar   `testfill:"ref:defaultTenant"`
+				Name   string `testfill:"acme"`
+			}
+
+			result, err := testfill.Fill(Account{})
+			require.NoError(t, err)
+
+			require.Same(t, tenant, result.Tenant)
+			require.Equal(t, "acme", result.Name)
+		})
+
+		t.Run("fills elements with the same instance", func(t *testing.T) {
+			type Member struct {
+				Tenant *Bar `testfill:"ref:defaultTenant"`
+			}
+			type Team struct {
+				Members []Member `testfill:"fill:2"`
+			}
+
+			result, err := testfill.Fill(Team{})
+			require.NoError(t, err)
+
+			require.Same(t, tenant, result.Members[0].Tenant)
+			require.Same(t, result.Members[0].Tenant, result.Members[1].Tenant)
+		})
+
+		t.Run("missing object", func(t *testing.T) {
+			type Account struct {
+				Tenant *Bar `testfill:"ref:unknownTenant"`
+			}
+
+			_, err := testfill.Fill(Account{})
+
+			require.EqualError(t, err, "testfill: failed to set field Tenant: no object registered as unknownTenant")
+		})
+
+		t.Run("type mismatch", func(t *testing.T) {
+			type Account struct {
+				Tenant Bar `testfill:"ref:defaultTenant"`
+			}
+
+			_, err := testfill.Fill(Account{})
+
+			require.EqualError(t, err, "testfill: failed to set field Tenant: object defaultTenant is *testfill_test.Bar, but field expects testfill_test.Bar")
+		})
+	})
+
+	t.Run("fake", func(t *testing.T) {
+		type Person struct {
+			Name  string `testfill:"fake:name"`
+			Email string `testfill:"fake:email"`
+			City  string `testfill:"fake:city"`
+		}
+
+		t.Run("generates values of each category", func(t *testing.T) {
+			result, err := testfill.Fill(Person{})
+			require.NoError(t, err)
+
+			require.Regexp(t, `^[A-Z][a-z]+ [A-Z][a-z]+$`, result.Name)
+			require.Regexp(t, `^[a-z]+\.[a-z]+@example\.com$`, result.Email)
+			require.Regexp(t, `^[A-Z][a-z]+$`, result.City)
+		})
+
+		t.Run("is reproducible with WithSeed", func(t *testing.T) {
+			first, err := testfill.FillWithOptions([]Person{{}, {}}, testfill.WithSeed(7))
+			require.NoError(t, err)
+			second, err := testfill.FillWithOptions([]Person{{}, {}}, testfill.WithSeed(7))
+			require.NoError(t, err)
+
+			require.Equal(t, first, second)
+		})
+
+		t.Run("errors on unknown categories", func(t *testing.T) {
+			type Invalid struct {
+				Phone string `testfill:"fake:phone"`
+			}
+
+			_, err := testfill.Fill(Invalid{})
+
+			require.EqualError(t, err, `testfill: failed to set field Phone: unknown fake category "phone"`)
+		})
+
+		t.Run("errors on non-string fields", func(t *testing.T) {
+			type Invalid struct {
+				Age int `testfill:"fake:name"`
+			}
+
+			_, err := testfill.Fill(Invalid{})
+
+			require.EqualError(t, err, "testfill: failed to set field Age: fake is not supported for int fields")
+			require.ErrorIs(t, err, testfill.ErrUnsupported)
+		})
+	})
+
+	t.Run("flags", func(t *testing.T) {
+		type Perm uint
+		const (
+			Read Perm = 1 << iota
+			Write
+			Exec
+		)
+		testfill.RegisterFlags(reflect.TypeOf(Perm(0)), map[string]uint64{
+			"Read": uint64(Read), "Write": uint64(Write), "Exec": uint64(Exec),
+		})
+
+		t.Run("ORs the named flags", func(t *testing.T) {
+			type Grant struct {
+				Perm   Perm `testfill:"flags:Read|Write"`
+				All    Perm `testfill:"flags:Read | Write | Exec"`
+				Caller Perm `testfill:"flags:Exec"`
+			}
+
+			result, err := testfill.Fill(Grant{Caller: Read})
+			require.NoError(t, err)
+
+			require.Equal(t, Read|Write, result.Perm)
+			require.Equal(t, Read|Write|Exec, result.All)
+			require.Equal(t, Read, result.Caller)
+		})
+
+		t.Run("errors on unknown flag names", func(t *testing.T) {
+			type Grant struct {
+				Perm Perm `testfill:"flags:Read|Delete"`
+			}
+
+			_, err := testfill.Fill(Grant{})
+
+			require.EqualError(t, err, `testfill: failed to set field Perm: unknown flag "Delete" for testfill_test.Perm`)
+		})
+
+		t.Run("errors on types without flags", func(t *testing.T) {
+			type Grant struct {
+				Mode uint `testfill:"flags:Read"`
+			}
+
+			_, err := testfill.Fill(Grant{})
+
+			require.EqualError(t, err, "testfill: failed to set field Mode: no flags registered for uint")
+		})
+
+		t.Run("errors on flags the type cannot hold", func(t *testing.T) {
+			type Small uint8
+			type Signed int8
+			testfill.RegisterFlags(reflect.TypeOf(Small(0)), map[string]uint64{"Low": 1, "High": 256})
+			testfill.RegisterFlags(reflect.TypeOf(Signed(0)), map[string]uint64{"Low": 1, "Sign": 128})
+			type Grant struct {
+				Small  Small  `testfill:"flags:Low|High"`
+				Signed Signed `testfill:"flags:Sign"`
+			}
+
+			_, err := testfill.Fill(Grant{})
+			require.EqualError(t, err, "testfill: failed to set field Small: flags Low|High value 257 overflows testfill_test.Small")
+
+			_, err = testfill.Fill(Grant{Small: 1})
+			require.EqualError(t, err, "testfill: failed to set field Signed: flags Sign value 128 overflows testfill_test.Signed")
+		})
+	})
+
+	t.Run("WithFieldHook", func(t *testing.T) {
+		type Address struct {
+			City string `testfill:"Paris"`
+			Zip  string
+		}
+		type Customer struct {
+			Name      string    `testfill:"Jane"`
+			Addresses []Address `testfill:"fill:2"`
+			Note      string    `testfill:"from tag"`
+		}
+
+		t.Run("fills the paths the hook handles and falls through otherwise", func(t *testing.T) {
+			var paths []string
+			hook := func(path string, typ reflect.Type) (reflect.Value, bool, error) {
+				paths = append(paths, path)
+				switch path {
+				case "Addresses[1].City":
+					return reflect.ValueOf("Lisbon"), true, nil
+				case "Addresses[0].Zip":
+					return reflect.ValueOf("75001"), true, nil
+				}
+				return reflect.Value{}, false, nil
+			}
+
+			result, err := testfill.FillWithOptions(Customer{Note: "caller"}, testfill.WithFieldHook(hook))
+			require.NoError(t, err)
+
+			require.Equal(t, Customer{
+				Name:      "Jane",
+				Addresses: []Address{{City: "Paris", Zip: "75001"}, {City: "Lisbon"}},
+				Note:      "caller",
+			}, result)
+			require.Equal(t, []string{"Name", "Addresses", "Addresses[0].City", "Addresses[0].Zip", "Addresses[1].City", "Addresses[1].Zip"}, paths)
+		})
+
+		t.Run("reports hook errors with the field path", func(t *testing.T) {
+			hook := func(path string, typ reflect.Type) (reflect.Value, bool, error) {
+				if path == "Addresses[0].City" {
+					return reflect.Value{}, false, errors.New("no cities left")
+				}
+				return reflect.Value{}, false, nil
+			}
+
+			_, err := testfill.FillWithOptions(Customer{}, testfill.WithFieldHook(hook))
+
+			require.ErrorContains(t, err, "failed to set field Addresses[0].City: no cities left")
+		})
+
+		t.Run("rejects values of the wrong type", func(t *testing.T) {
+			hook := func(path string, typ reflect.Type) (reflect.Value, bool, error) {
+				return reflect.ValueOf(42), path == "Name", nil
+			}
+
+			_, err := testfill.FillWithOptions(Customer{}, testfill.WithFieldHook(hook))
+
+			require.EqualError(t, err, "testfill: failed to set field Name: field hook returned int, but field expects string")
+		})
+	})
+
+	t.Run("literal values that look like directives", func(t *testing.T) {
+		type Schedule struct {
+			Cadence  string            `testfill:"repeat:daily"`
+			Window   string            `testfill:"range:10,max:20"`
+			Source   string            `testfill:"provider:aws,region"`
+			Repeats  map[string]string `testfill:"repeat:3"`
+			Limits   map[string]int    `testfill:"range:10,max:20"`
+			Cloud    map[string]string `testfill:"provider:aws,region:us"`
+			Cadences []string          `testfill:"repeat:daily,weekly"`
+			Ranges   []string          `testfill:"range:a,b"`
+			Sources  []string          `testfill:"provider:aws,gcp"`
+		}
+
+		result, err := testfill.Fill(Schedule{})
+		require.NoError(t, err)
+
+		require.Equal(t, Schedule{
+			Cadence:  "repeat:daily",
+			Window:   "range:10,max:20",
+			Source:   "provider:aws,region",
+			Repeats:  map[string]string{"repeat": "3"},
+			Limits:   map[string]int{"range": 10, "max": 20},
+			Cloud:    map[string]string{"provider": "aws", "region": "us"},
+			Cadences: []string{"repeat:daily", "weekly"},
+			Ranges:   []string{"range:a", "b"},
+			Sources:  []string{"provider:aws", "gcp"},
+		}, result)
+	})
+
+	t.Run("registries are safe for concurrent registration and fills", func(t *testing.T) {
+		type Celsius float64
+		type Tenant struct {
+			ID string
+		}
+		type Reading struct {
+			Unit  string  `testfill:"@concurrentUnit"`
+			Value Celsius `testfill:"21.5"`
+			Probe string  `testfill:"provider:probe"`
+			Owner *Tenant `testfill:"ref:concurrentOwner"`
+		}
+		defer testfill.RegisterProvider(nil)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				testfill.RegisterMacro("concurrentUnit", "celsius")
+				testfill.RegisterConverter(reflect.TypeOf(Celsius(0)), func(s string) (interface{}, error) {
+					return strconv.ParseFloat(s, 64)
+				})
+				testfill.RegisterProvider(func(key string) (string, error) { return key, nil })
+				testfill.RegisterObject("concurrentOwner", &Tenant{ID: "acme"})
+				testfill.RegisterZeroChecker(reflect.TypeOf(Celsius(0)), func(v reflect.Value) bool { return v.Float() == 0 })
+				testfill.RegisterPostFill(reflect.TypeOf(Reading{}), func(reflect.Value) error { return nil })
+			}()
+			go func() {
+				defer wg.Done()
+				_, _ = testfill.Fill(Reading{})
+			}()
+		}
+		wg.Wait()
+
+		result, err := testfill.Fill(Reading{})
+		require.NoError(t, err)
+
+		require.Equal(t, Reading{Unit: "celsius", Value: 21.5, Probe: "probe", Owner: &Tenant{ID: "acme"}}, result)
+	})
+}
+
+func BenchmarkFillFactory(b *testing.B) {
+	testfill.RegisterFactory("BenchName", func(first string, last string) string {
+		return first + " " + last
+	})
+	testfill.RegisterFactory("BenchCount", func(n int) int {
+		return n * 2
+	})
+	type Fixture struct {
+		Name  string `testfill:"factory:BenchName:Ada:Lovelace"`
+		Alias string `testfill:"factory:BenchName:Grace:Hopper"`
+		Count int    `testfill:"factory:BenchCount:21"`
+		Total int    `testfill:"factory:BenchCount:50"`
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := testfill.Fill(Fixture{}); err != nil {
+			b.Fatal(err)
+		}
+	}
 }